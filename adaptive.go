@@ -0,0 +1,150 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+const (
+	adaptiveInterval = 10 * time.Second
+	adaptiveRows     = 32   // scanlines sampled per pass, spread over the screen
+	adaptiveSmooth   = 0.3  // how far each pass moves toward the new nudge
+	maxDimNudge      = 0.15 // brightness taken off a full-white screen
+	maxWarmNudge     = 800  // Kelvin taken off a strongly blue screen
+)
+
+// nudge is the content-adaptive offset applied on top of the sliders.
+type nudge struct {
+	tempK      float64
+	brightness float64
+}
+
+func (n nudge) apply(s Settings) Settings {
+	s.TempK = int(math.Round(float64(s.TempK) - n.tempK))
+	s.Brightness = math.Max(s.Brightness-n.brightness, 0.10)
+	return s
+}
+
+// screenStats is what the sampler measured: mean luma and how much blue
+// dominates, both 0..1.
+type screenStats struct {
+	luma float64
+	blue float64
+}
+
+// nudgeFor maps screen content to the offset it calls for. Bright pages get
+// dimmed and blue-heavy content gets warmed, mid-grey content is left alone.
+func nudgeFor(st screenStats) nudge {
+	return nudge{
+		tempK:      maxWarmNudge * math.Max(0, st.blue-0.5) * 2,
+		brightness: maxDimNudge * math.Max(0, st.luma-0.5) * 2,
+	}
+}
+
+// setAdaptive starts or stops the sampling loop and persists the choice.
+func (u *uiState) setAdaptive(on bool) {
+	if on == (u.adaptiveStop != nil) {
+		return
+	}
+	u.cfg.Adaptive = on
+	u.saveConfig()
+
+	if !on {
+		close(u.adaptiveStop)
+		u.adaptiveStop = nil
+		u.nudge = nudge{}
+		u.scheduleApply(u.target())
+		return
+	}
+	stop := make(chan struct{})
+	u.adaptiveStop = stop
+	go u.adaptiveLoop(stop)
+}
+
+func (u *uiState) adaptiveLoop(stop chan struct{}) {
+	tick := time.NewTicker(adaptiveInterval)
+	defer tick.Stop()
+	for {
+		st, err := sampleScreen()
+		fyne.Do(func() {
+			if u.adaptiveStop != stop {
+				return
+			}
+			if err != nil {
+				u.out.SetText("Adaptive mode: " + err.Error())
+				return
+			}
+			want := nudgeFor(st)
+			u.nudge.tempK += (want.tempK - u.nudge.tempK) * adaptiveSmooth
+			u.nudge.brightness += (want.brightness - u.nudge.brightness) * adaptiveSmooth
+			u.scheduleApply(u.target())
+		})
+
+		select {
+		case <-stop:
+			return
+		case <-tick.C:
+		}
+	}
+}
+
+// sampleScreen reads a handful of scanlines from the X root window. The
+// framebuffer is read before the gamma ramps, so our own tint doesn't feed
+// back into the measurement. Wayland does not expose the screen this way.
+func sampleScreen() (screenStats, error) {
+	X, err := xgb.NewConn()
+	if err != nil {
+		return screenStats{}, err
+	}
+	defer X.Close()
+
+	setup := xproto.Setup(X)
+	scr := setup.DefaultScreen(X)
+	bpp := 0
+	for _, f := range setup.PixmapFormats {
+		if f.Depth == scr.RootDepth {
+			bpp = int(f.BitsPerPixel)
+		}
+	}
+	if bpp != 32 {
+		return screenStats{}, fmt.Errorf("unsupported pixel format (%d bpp)", bpp)
+	}
+
+	w, h := scr.WidthInPixels, scr.HeightInPixels
+	var sumR, sumG, sumB float64
+	n := 0
+	for row := 0; row < adaptiveRows; row++ {
+		y := int16((int(h) * (2*row + 1)) / (2 * adaptiveRows))
+		img, err := xproto.GetImage(X, xproto.ImageFormatZPixmap, xproto.Drawable(scr.Root),
+			0, y, w, 1, math.MaxUint32).Reply()
+		if err != nil {
+			return screenStats{}, err
+		}
+		for p := 0; p+3 < len(img.Data); p += 4 * 8 { // every 8th pixel is plenty
+			b, g, r := img.Data[p], img.Data[p+1], img.Data[p+2]
+			if setup.ImageByteOrder != xproto.ImageOrderLSBFirst {
+				r, g, b = img.Data[p+1], img.Data[p+2], img.Data[p+3]
+			}
+			sumR += float64(r)
+			sumG += float64(g)
+			sumB += float64(b)
+			n++
+		}
+	}
+	if n == 0 {
+		return screenStats{}, errors.New("empty screen sample")
+	}
+
+	r, g, b := sumR/float64(n)/255, sumG/float64(n)/255, sumB/float64(n)/255
+	st := screenStats{luma: 0.2126*r + 0.7152*g + 0.0722*b}
+	if sum := r + g + b; sum > 0.05 {
+		st.blue = math.Min(b/sum*1.5, 1) // 0.5 for neutral grey, 1 for pure blue
+	}
+	return st, nil
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ---- ArgyllCMS: CGATS .cal curves and .ti3 measurements ----
+
+// cgatsTable is one table of a CGATS file: its field names and rows.
+type cgatsTable struct {
+	fields []string
+	rows   [][]string
+}
+
+func (t cgatsTable) column(name string) int {
+	for i, f := range t.fields {
+		if f == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// readCGATS reads every table in a CGATS text file. Keywords other than
+// the data format and data blocks are skipped.
+func readCGATS(rd io.Reader) ([]cgatsTable, error) {
+	var tables []cgatsTable
+	var cur *cgatsTable
+	inFormat, inData := false, false
+	sc := bufio.NewScanner(rd)
+	for n := 1; sc.Scan(); n++ {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		switch {
+		case fields[0] == "BEGIN_DATA_FORMAT":
+			tables = append(tables, cgatsTable{})
+			cur, inFormat = &tables[len(tables)-1], true
+		case fields[0] == "END_DATA_FORMAT":
+			inFormat = false
+		case fields[0] == "BEGIN_DATA":
+			if cur == nil {
+				return nil, fmt.Errorf("line %d: data before its format", n)
+			}
+			inData = true
+		case fields[0] == "END_DATA":
+			inData, cur = false, nil
+		case inFormat:
+			cur.fields = append(cur.fields, fields...)
+		case inData:
+			if len(fields) != len(cur.fields) {
+				return nil, fmt.Errorf("line %d: expected %d values", n, len(cur.fields))
+			}
+			cur.rows = append(cur.rows, fields)
+		}
+	}
+	return tables, sc.Err()
+}
+
+// argyllMeasurement is what an ArgyllCMS file says about a panel.
+type argyllMeasurement struct {
+	curves *Ramp // calibration curves, nil if the file has none
+	whiteX float64
+	whiteY float64 // measured white chromaticity, 0, 0 if not measured
+}
+
+// readArgyll reads the calibration curves of a dispcal .cal file or a
+// .ti3 with an embedded CAL table, and the measured white of a .ti3: the
+// XYZ of its brightest neutral patch.
+func readArgyll(rd io.Reader) (argyllMeasurement, error) {
+	tables, err := readCGATS(rd)
+	if err != nil {
+		return argyllMeasurement{}, err
+	}
+	var m argyllMeasurement
+	for _, t := range tables {
+		if t.column("RGB_I") >= 0 && m.curves == nil {
+			r, err := argyllCurves(t)
+			if err != nil {
+				return argyllMeasurement{}, err
+			}
+			m.curves = &r
+		}
+		if t.column("XYZ_Y") >= 0 && m.whiteX == 0 {
+			m.whiteX, m.whiteY = argyllWhite(t)
+		}
+	}
+	if m.curves == nil && m.whiteX == 0 {
+		return argyllMeasurement{}, errors.New("no calibration curves or white patch found")
+	}
+	return m, nil
+}
+
+// argyllCurves converts a CAL table, whose RGB_R/G/B columns are 0..1
+// outputs for the RGB_I inputs, to a ramp of the same size.
+func argyllCurves(t cgatsTable) (Ramp, error) {
+	cols := []int{t.column("RGB_R"), t.column("RGB_G"), t.column("RGB_B")}
+	if cols[0] < 0 || cols[1] < 0 || cols[2] < 0 || len(t.rows) < 2 {
+		return Ramp{}, errors.New("CAL table without RGB_R, RGB_G and RGB_B curves")
+	}
+	r := Ramp{R: make([]uint16, len(t.rows)), G: make([]uint16, len(t.rows)), B: make([]uint16, len(t.rows))}
+	out := [][]uint16{r.R, r.G, r.B}
+	for i, row := range t.rows {
+		for c, col := range cols {
+			v, err := strconv.ParseFloat(row[col], 64)
+			if err != nil || v < 0 || v > 1 {
+				return Ramp{}, fmt.Errorf("CAL entry %d: bad value %q", i, row[col])
+			}
+			out[c][i] = uint16(math.Round(v * math.MaxUint16))
+		}
+	}
+	return r, nil
+}
+
+// argyllWhite returns the chromaticity of the brightest patch with equal
+// RGB device values, or 0, 0 if there is none.
+func argyllWhite(t cgatsTable) (x, y float64) {
+	idx := []int{t.column("RGB_R"), t.column("RGB_G"), t.column("RGB_B"), t.column("XYZ_X"), t.column("XYZ_Y"), t.column("XYZ_Z")}
+	for _, i := range idx {
+		if i < 0 {
+			return 0, 0
+		}
+	}
+	best := -1.0
+	for _, row := range t.rows {
+		var v [6]float64
+		for k, i := range idx {
+			var err error
+			if v[k], err = strconv.ParseFloat(row[i], 64); err != nil {
+				return 0, 0
+			}
+		}
+		sum := v[3] + v[4] + v[5]
+		if v[0] != v[1] || v[1] != v[2] || v[0] <= best || sum <= 0 {
+			continue
+		}
+		best, x, y = v[0], v[3]/sum, v[4]/sum
+	}
+	return x, y
+}
+
+// panelBase folds a measured white into the curves so the sliders are
+// relative to the panel rather than to an ideal D65 one: each channel is
+// scaled by how far the panel's white is from D65, so 6500K on the slider
+// comes out as 6500K on the panel. curves may be nil.
+func panelBase(curves *Ramp, whiteX, whiteY float64) Ramp {
+	base := identityRamp(exportRampSize)
+	if curves != nil {
+		base = Ramp{R: append([]uint16(nil), curves.R...), G: append([]uint16(nil), curves.G...), B: append([]uint16(nil), curves.B...)}
+	}
+	if whiteX == 0 && whiteY == 0 {
+		return base
+	}
+	pr, pg, pb := normalizeWhite(xyRGB(whiteX, whiteY))
+	kr, kg, kb := 1/pr, 1/pg, 1/pb
+	m := math.Max(kr, math.Max(kg, kb))
+	for c, k := range []float64{kr / m, kg / m, kb / m} {
+		ch := [][]uint16{base.R, base.G, base.B}[c]
+		for i, v := range ch {
+			ch[i] = uint16(math.Round(float64(v) * k))
+		}
+	}
+	return base
+}
+
+func isArgyllFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".cal" || ext == ".ti3"
+}
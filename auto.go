@@ -0,0 +1,264 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// As in redshift, dusk and dawn run by default from the sun being
+// defaultDayElevation degrees up to defaultNightElevation below the
+// horizon; in between the sliders move a little every autoInterval.
+const (
+	autoInterval          = time.Minute
+	defaultDayElevation   = 3.0
+	defaultNightElevation = -6.0
+)
+
+// autoPhase returns how far into the night l is at t: 0 while the sun is
+// above high degrees, 1 once it is below low, and in between during
+// twilight, in proportion to the elevation.
+func autoPhase(t time.Time, l Location, high, low float64) float64 {
+	e := sunElevation(t, l)
+	return (high - e) / (high - low)
+}
+
+// clockPhase is autoPhase by the clock instead: night starts fading in at
+// nightAt and day at dayAt, each taking fade. Solar times need loc.
+func clockPhase(t time.Time, dayAt, nightAt TimeSpec, fade time.Duration, loc *Location) (float64, error) {
+	type edge struct {
+		at    time.Time
+		night bool
+	}
+	var edges []edge
+	var errs []error
+	for _, dd := range []int{-1, 0, 1} {
+		day := t.AddDate(0, 0, dd)
+		for _, e := range []struct {
+			spec  TimeSpec
+			night bool
+		}{{dayAt, false}, {nightAt, true}} {
+			at, err := e.spec.resolve(day, loc)
+			if err != nil {
+				if dd == 0 {
+					errs = append(errs, err)
+				}
+				continue
+			}
+			edges = append(edges, edge{at, e.night})
+		}
+	}
+	slices.SortFunc(edges, func(a, b edge) int { return a.at.Compare(b.at) })
+	var last *edge
+	for i := range edges {
+		if !edges[i].at.After(t) {
+			last = &edges[i]
+		}
+	}
+	if last == nil {
+		return 0, errors.Join(errs...)
+	}
+	p := 1.0
+	if fade > 0 {
+		p = min(float64(t.Sub(last.at))/float64(fade), 1)
+	}
+	if !last.night {
+		p = 1 - p
+	}
+	return p, errors.Join(errs...)
+}
+
+// setAuto starts or stops Auto, which follows the sun or the clock
+// between the day and night values. It takes over from the
+// schedule, which would otherwise fight it for the sliders.
+func (u *uiState) setAuto(on bool) {
+	if on == (u.autoStop != nil) {
+		return
+	}
+	if on {
+		if _, ok := u.location(); !ok && !u.cfg.AutoClock && !u.cfg.GeoClue && !u.cfg.IPLocation {
+			u.out.SetText("Auto needs a location; set one under Places › Location…")
+			return
+		}
+		u.setSchedule(false)
+		if u.cfg.RedshiftDaemon {
+			u.setRedshiftDaemon(false)
+		}
+	}
+	u.cfg.Auto = on
+	u.saveConfig()
+	if !on {
+		close(u.autoStop)
+		u.autoStop = nil
+		u.setUpcoming(upcoming{})
+		return
+	}
+	u.restartAuto()
+	u.locate()
+}
+
+// restartAuto picks up a new location or day and night values. Following
+// the sun without a location, Auto stays on but waits for one.
+func (u *uiState) restartAuto() {
+	if u.autoStop != nil {
+		close(u.autoStop)
+	}
+	stop := make(chan struct{})
+	u.autoStop = stop
+	l, ok := u.location()
+	var phase func(time.Time) (float64, error)
+	switch {
+	case u.cfg.AutoClock:
+		var loc *Location
+		if ok {
+			loc = &l
+		}
+		dayAt, nightAt := u.cfg.AutoDayAt, u.cfg.AutoNightAt
+		fade := time.Duration(u.cfg.AutoFadeMinutes) * time.Minute
+		phase = func(t time.Time) (float64, error) { return clockPhase(t, dayAt, nightAt, fade, loc) }
+	case ok:
+		high, low := u.cfg.AutoElevationHigh, u.cfg.AutoElevationLow
+		phase = func(t time.Time) (float64, error) { return autoPhase(t, l, high, low), nil }
+	default:
+		u.setUpcoming(upcoming{})
+		u.out.SetText("Auto: finding where you are…")
+		return
+	}
+	go u.autoLoop(phase, u.cfg.AutoDay, u.cfg.AutoNight, stop)
+}
+
+// useForAutoDay and useForAutoNight store the current sliders as where
+// Auto settles by day or by night.
+func (u *uiState) useForAutoDay() {
+	u.cfg.AutoDay = u.current()
+	u.saveConfig()
+	u.out.SetText("Auto day values updated.")
+	if u.autoStop != nil {
+		u.restartAuto()
+	}
+}
+
+func (u *uiState) useForAutoNight() {
+	u.cfg.AutoNight = u.current()
+	u.saveConfig()
+	u.out.SetText("Auto night values updated.")
+	if u.autoStop != nil {
+		u.restartAuto()
+	}
+}
+
+// autoLoop moves the sliders toward the day or night values as the sun
+// rises and sets. It only touches them when the mix has changed, so a
+// tweak made by hand in the middle of the day or night stays put until
+// the next twilight.
+func (u *uiState) autoLoop(phase func(time.Time) (float64, error), day, night Settings, stop chan struct{}) {
+	var last Settings
+	var lastErr string
+	tick := time.NewTicker(autoInterval)
+	defer tick.Stop()
+	for {
+		p, err := phase(time.Now())
+		msg := ""
+		if err != nil {
+			msg = err.Error()
+		}
+		if msg != "" && msg != lastErr {
+			fyne.Do(func() {
+				if u.autoStop == stop {
+					u.out.SetText("Auto: " + msg)
+				}
+			})
+		}
+		lastErr = msg
+		n := nextSwitch(phase, time.Now())
+		fyne.Do(func() {
+			if u.autoStop == stop {
+				u.setUpcoming(n)
+			}
+		})
+		s := lerp(day, night, p)
+		if s != last {
+			last = s
+			fyne.Do(func() {
+				if u.autoStop == stop {
+					u.setSliders(s)
+					u.scheduleApply(u.target())
+					u.syncTray()
+				}
+			})
+		}
+		select {
+		case <-stop:
+			return
+		case <-tick.C:
+		}
+	}
+}
+
+// showAutoTimes sets when Auto turns to night and back: between two sun
+// elevations, or by the clock.
+func (u *uiState) showAutoTimes() {
+	high := widget.NewEntry()
+	high.SetText(formatNum("%g", u.cfg.AutoElevationHigh))
+	low := widget.NewEntry()
+	low.SetText(formatNum("%g", u.cfg.AutoElevationLow))
+	elevation := widget.NewFormItem("Night below (°)", low)
+	elevation.HintText = "Sun elevation; negative is below the horizon"
+	byClock := widget.NewCheck("Switch at set times instead of following the sun", nil)
+	byClock.Checked = u.cfg.AutoClock
+	night := widget.NewEntry()
+	night.SetText(string(u.cfg.AutoNightAt))
+	night.SetPlaceHolder("21:00 or sunset")
+	day := widget.NewEntry()
+	day.SetText(string(u.cfg.AutoDayAt))
+	day.SetPlaceHolder("07:00 or sunrise")
+	fade := widget.NewEntry()
+	fade.SetText(strconv.Itoa(u.cfg.AutoFadeMinutes))
+
+	dialog.ShowForm("Day and night", "Save", "Cancel", []*widget.FormItem{
+		widget.NewFormItem("Day above (°)", high),
+		elevation,
+		widget.NewFormItem("", byClock),
+		widget.NewFormItem("Night starts", night),
+		widget.NewFormItem("Day starts", day),
+		widget.NewFormItem("Transition (min)", fade),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+		nightAt, dayAt := TimeSpec(strings.TrimSpace(night.Text)), TimeSpec(strings.TrimSpace(day.Text))
+		for _, at := range []TimeSpec{nightAt, dayAt} {
+			// Any location will do to check the syntax of solar times.
+			if _, err := at.resolve(time.Now(), &Location{}); err != nil {
+				u.out.SetText("Times not saved: " + err.Error())
+				return
+			}
+		}
+		hi, herr := parseNum(high.Text)
+		lo, lerr := parseNum(low.Text)
+		if herr != nil || lerr != nil || hi <= lo || hi > 90 || lo < -90 {
+			u.out.SetText("Times not saved: the day elevation must be above the night one, within ±90°.")
+			return
+		}
+		minutes, err := strconv.Atoi(strings.TrimSpace(fade.Text))
+		if err != nil || minutes < 0 {
+			u.out.SetText(fmt.Sprintf("Times not saved: %q is not a number of minutes", fade.Text))
+			return
+		}
+		u.cfg.AutoElevationHigh, u.cfg.AutoElevationLow = hi, lo
+		u.cfg.AutoClock = byClock.Checked
+		u.cfg.AutoNightAt, u.cfg.AutoDayAt, u.cfg.AutoFadeMinutes = nightAt, dayAt, minutes
+		u.saveConfig()
+		if u.autoStop != nil {
+			u.restartAuto()
+		}
+		u.out.SetText("Day and night times saved.")
+	}, u.win)
+}
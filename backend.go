@@ -0,0 +1,608 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Backend is one way of getting settings on screen. Apply and Reset are
+// called off the UI thread with a context that carries the apply timeout;
+// s has already passed validate.
+type Backend interface {
+	Name() string // also its Config.Backend value
+	Apply(ctx context.Context, s Settings) error
+	Reset(ctx context.Context) error
+	Capabilities() Capabilities
+}
+
+// Capabilities says what a backend can do. The
+// apply pipeline falls back to native ramps for settings the chosen
+// backend can't show.
+type Capabilities struct {
+	Temperature  bool // warms the white
+	Brightness   bool // dims
+	Gamma        bool // changes the gamma
+	ChannelGamma bool // separate R, G, B gamma, which blue reduction needs
+	Curves       bool // shapes the ramp itself: base correction, reference curve
+	WhitePoint   bool // any white point, not only the blackbody: xy and tint
+	PerOutput    bool // different settings per output, for focus emphasis
+	Remote       bool // works on a screen reached over SSH
+}
+
+// lacks names what need asks for that c doesn't have.
+func (c Capabilities) lacks(need Capabilities) []string {
+	var missing []string
+	for _, f := range []struct {
+		name      string
+		has, want bool
+	}{
+		{"temperature", c.Temperature, need.Temperature},
+		{"brightness", c.Brightness, need.Brightness},
+		{"gamma", c.Gamma, need.Gamma},
+		{"blue reduction", c.ChannelGamma, need.ChannelGamma},
+		{"base correction or reference curve", c.Curves, need.Curves},
+		{"white point", c.WhitePoint, need.WhitePoint},
+		{"per-monitor settings", c.PerOutput, need.PerOutput},
+		{"remote screens", c.Remote, need.Remote},
+	} {
+		if f.want && !f.has {
+			missing = append(missing, f.name)
+		}
+	}
+	return missing
+}
+
+// or returns what either c or d can do.
+func (c Capabilities) or(d Capabilities) Capabilities {
+	return Capabilities{
+		Temperature:  c.Temperature || d.Temperature,
+		Brightness:   c.Brightness || d.Brightness,
+		Gamma:        c.Gamma || d.Gamma,
+		ChannelGamma: c.ChannelGamma || d.ChannelGamma,
+		Curves:       c.Curves || d.Curves,
+		WhitePoint:   c.WhitePoint || d.WhitePoint,
+		PerOutput:    c.PerOutput || d.PerOutput,
+		Remote:       c.Remote || d.Remote,
+	}
+}
+
+// limiter is implemented by backends whose limits differ from redshift's.
+type limiter interface {
+	Limits() Ranges
+}
+
+// backendLimits returns the values b can show.
+func backendLimits(b Backend) Ranges {
+	if l, ok := b.(limiter); ok {
+		return l.Limits()
+	}
+	return redshiftLimits
+}
+
+// statusReporter is implemented by backends with more to say after a
+// successful apply than "Applied.".
+type statusReporter interface {
+	Status() string
+}
+
+const (
+	backendRedshift = "redshift"
+	backendRamps    = "randr"
+)
+
+// availability is implemented by backends that need something installed
+// or a particular session; the backend menu only offers those that are
+// available.
+type availability interface {
+	Available() bool
+}
+
+func available(b Backend) bool {
+	a, ok := b.(availability)
+	return !ok || a.Available()
+}
+
+// newBackends lists the backends Config.Backend can pick, the default first.
+func newBackends(u *uiState) []Backend {
+	backends := []Backend{&redshiftBackend{u}, &nvidiaBackend{u}, u.ramps, &gammastepBackend{u: u}, &wlsunsetBackend{u: u}, &gammarelayBackend{u}, &kwinBackend{u: u}, &gnomeBackend{u: u}, &xsctBackend{u}, &hyprsunsetBackend{u: u}, &xrandrBackend{u}, &drmBackend{u}, &customBackend{u}, u.overlay}
+	return append(backends, platformBackends(u)...)
+}
+
+// backendPriority is the order defaultBackend probes in. The platform's
+// own comes first. On X11 our own ramps need nothing installed and cost
+// no process per change, then the command-line tools. Wayland needs a
+// compositor protocol: the desktop's own night light where it fights
+// everyone else's, then the generic tools. The overlay is the last
+// resort on both. Without either there is only KMS.
+func backendPriority() []string {
+	var names []string
+	if platformBackend != "" {
+		names = append(names, platformBackend)
+	}
+	if consoleSession() {
+		return append(names, backendDRM)
+	}
+	if waylandSession() {
+		return append(names, backendKWin, backendGNOME, backendHyprsunset, backendGammarelay, backendGammastep, backendWlsunset, backendOverlay)
+	}
+	return append(names, backendRamps, backendRedshift, backendGammastep, backendXsct, backendXrandr, backendOverlay)
+}
+
+// defaultBackend is what an unset Config.Backend means here: the first
+// backend in priority order that is available, or "" if none is. Safe to
+// call from any goroutine.
+func (u *uiState) defaultBackend() string {
+	for _, name := range backendPriority() {
+		for _, b := range u.backends {
+			if b.Name() == name && available(b) {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// backend returns the backend the user chose. Safe to call from any
+// goroutine.
+func (u *uiState) backend() Backend {
+	return u.backends[u.chosen.Load()]
+}
+
+// chooseBackend selects the backend named name, "" meaning the first
+// available one, or redshift if there is no such backend, and reports
+// whether it was found.
+func (u *uiState) chooseBackend(name string) bool {
+	if name == "" {
+		name = u.defaultBackend()
+	}
+	for i, b := range u.backends {
+		if b.Name() == name {
+			u.chosen.Store(int32(i))
+			return true
+		}
+	}
+	u.chosen.Store(0)
+	return false
+}
+
+// setBackend switches to the backend named name, "" to detect one,
+// resetting what the old one left on screen first.
+func (u *uiState) setBackend(name string) {
+	u.cfg.Backend = name
+	u.saveConfig()
+	old := u.backend()
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		old.Reset(ctx)
+		u.chooseBackend(name)
+		fyne.Do(func() {
+			u.syncControls()
+			u.scheduleApply(u.target())
+			u.out.SetText(u.backendStatus())
+		})
+	}()
+}
+
+// needs returns the capabilities showing s takes.
+func (u *uiState) needs(s Settings) Capabilities {
+	return Capabilities{
+		Temperature:  s.TempK != defaultSettings.TempK,
+		Brightness:   s.Brightness != 1,
+		Gamma:        s.Gamma != 1,
+		ChannelGamma: s.BlueReduction != 0,
+		Curves:       u.base.Load() != nil || u.reference.Load() != int32(refNone),
+		WhitePoint:   s.hasWhiteXY() || s.Tint != 0,
+		PerOutput:    u.perOutput(),
+		Remote:       u.remote.Load() != nil,
+	}
+}
+
+// backendFor picks the backend for s: the chosen one, unless s needs
+// something it lacks that our own ramps can do. A remote screen gets
+// redshift over SSH when the chosen backend only works here, which it is
+// whenever detection picked our ramps. Safe to call from any goroutine.
+func (u *uiState) backendFor(s Settings) Backend {
+	b := u.backend()
+	need := u.needs(s)
+	if need.Remote && !b.Capabilities().Remote {
+		for _, name := range remoteFallbacks {
+			if f := u.backendNamed(name); f != nil && !u.remoteLacks(name) {
+				return f
+			}
+		}
+		return u.backendNamed(backendRedshift) // to say that it's missing
+	}
+	if len(b.Capabilities().lacks(need)) > 0 && len(u.ramps.Capabilities().lacks(need)) == 0 && available(u.ramps) {
+		return u.ramps
+	}
+	return b
+}
+
+// remoteFallbacks are the backends backendFor tries in order on a remote
+// screen when the chosen one only works here. Each is named after the
+// command it runs there.
+var remoteFallbacks = []string{backendRedshift, backendXrandr, backendXsct}
+
+// backendNamed returns the backend called name, or nil.
+func (u *uiState) backendNamed(name string) Backend {
+	for _, b := range u.backends {
+		if b.Name() == name {
+			return b
+		}
+	}
+	return nil
+}
+
+// remoteLacks reports whether the remote target is known not to have
+// command. Until that is known it says no and asks the remote in the
+// background, so a slow ssh never holds up the caller. Safe to call from
+// any goroutine.
+func (u *uiState) remoteLacks(command string) bool {
+	r := u.remote.Load()
+	if r == nil {
+		return false
+	}
+	key := r.Host + " " + command
+	if v, loaded := u.remoteTools.LoadOrStore(key, nil); loaded {
+		has, known := v.(bool)
+		return known && !has
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		_, err := u.exec(ctx, "sh", "-c", "command -v "+shellQuote(command))
+		if ctx.Err() != nil {
+			u.remoteTools.Delete(key) // unreachable, not missing: ask again later
+			return
+		}
+		u.remoteTools.Store(key, err == nil)
+	}()
+	return false
+}
+
+// offered returns what the sliders can reach with the chosen backend:
+// its own capabilities, what backendFor can add from our ramps, and
+// brightness when the hardware or the overlay does the dimming.
+func (u *uiState) offered() Capabilities {
+	c := u.backendFor(defaultSettings).Capabilities()
+	if u.remote.Load() == nil && available(u.ramps) {
+		c = c.or(u.ramps.Capabilities())
+	}
+	if u.remote.Load() == nil && available(u.overlay) {
+		c = c.or(u.overlay.Capabilities())
+	}
+	if *u.ddcMode.Load() != hardwareOff || *u.blMode.Load() != hardwareOff {
+		c.Brightness = true
+	}
+	return c
+}
+
+// syncControls disables the sliders nothing would honour, rather than
+// letting them move without effect, and bounds the rest by the backend.
+func (u *uiState) syncControls() {
+	u.applyRanges()
+	c := u.offered()
+	u.tempK.SetEnabled(c.Temperature)
+	u.brightness.SetEnabled(c.Brightness)
+	u.gamma.SetEnabled(c.Gamma)
+	u.blue.SetEnabled(c.ChannelGamma)
+	u.tint.SetEnabled(c.WhitePoint)
+}
+
+// commandError turns a failed command into an error carrying its output,
+// which usually says more than the exit status.
+func commandError(out []byte, err error) error {
+	if msg := strings.TrimSpace(string(out)); msg != "" {
+		return errors.New(msg)
+	}
+	return err
+}
+
+// redshiftBackend runs redshift in one-shot mode, once per output target.
+type redshiftBackend struct {
+	u *uiState
+}
+
+func (b *redshiftBackend) Name() string { return backendRedshift }
+
+func (b *redshiftBackend) Available() bool {
+	return hasCommand("redshift") && !waylandSession()
+}
+
+func (b *redshiftBackend) Capabilities() Capabilities {
+	return Capabilities{Temperature: true, Brightness: true, Gamma: true, ChannelGamma: true, PerOutput: true, Remote: true}
+}
+
+func (b *redshiftBackend) Apply(ctx context.Context, s Settings) error {
+	for _, t := range b.u.outputTargets(s) {
+		gr, gg, gb := t.settings.channelGamma()
+		args := []string{
+			"-m", t.method,
+			"-P", // clear previous ramps so changes aren't compounded
+			"-O", fmt.Sprintf("%d", t.settings.TempK),
+			"-g", fmt.Sprintf("%.2f:%.2f:%.2f", gr, gg, gb),
+			"-b", fmt.Sprintf("%.2f", t.settings.Brightness),
+		}
+		if out, err := b.u.exec(ctx, "redshift", args...); err != nil {
+			return commandError(out, err)
+		}
+	}
+	return nil
+}
+
+func (b *redshiftBackend) Reset(ctx context.Context) error {
+	for _, t := range b.u.outputTargets(defaultSettings) {
+		if out, err := b.u.exec(ctx, "redshift", "-m", t.method, "-x"); err != nil {
+			return commandError(out, err)
+		}
+	}
+	return nil
+}
+
+// outputTarget is one redshift call: a method (all outputs, one CRTC or
+// one X screen) and the settings for it.
+type outputTarget struct {
+	method   string
+	settings Settings
+}
+
+// xScreens returns the X screens to drive, the one the user chose or all
+// of them, and the default one. Safe to call from any goroutine.
+func (u *uiState) xScreens() (screens []int, def int) {
+	count, def := xScreenLayout()
+	chosen := int(u.xScreen.Load()) - 1
+	for n := range count {
+		if chosen < 0 || n == chosen {
+			screens = append(screens, n)
+		}
+	}
+	return screens, def
+}
+
+// setXScreen drives only X screen n, or every screen when n is nil,
+// resetting the screens left behind first.
+func (u *uiState) setXScreen(n *int) {
+	u.cfg.XScreen = n
+	u.saveConfig()
+	b := u.backend()
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		b.Reset(ctx)
+		if n == nil {
+			u.xScreen.Store(0)
+		} else {
+			u.xScreen.Store(int32(*n + 1))
+		}
+		fyne.Do(func() { u.scheduleApply(u.target()) })
+	}()
+}
+
+// xScreenMenu picks the X screen to drive, or nil when there is only one.
+func (u *uiState) xScreenMenu() *fyne.Menu {
+	count, _ := xScreenLayout()
+	if count < 2 {
+		return nil
+	}
+	all := fyne.NewMenuItem("All screens", func() { u.setXScreen(nil) })
+	all.Checked = u.cfg.XScreen == nil
+	items := []*fyne.MenuItem{all, fyne.NewMenuItemSeparator()}
+	for n := range count {
+		item := fyne.NewMenuItem(fmt.Sprintf("Screen %d", n), func() { u.setXScreen(&n) })
+		item.Checked = u.cfg.XScreen != nil && *u.cfg.XScreen == n
+		items = append(items, item)
+	}
+	return fyne.NewMenu("", items...)
+}
+
+// pinnedMethod is the adjustment method the user chose for redshift and
+// gammastep, or "" to let us pick. Safe to call from any goroutine.
+func (u *uiState) pinnedMethod() string {
+	if m := u.method.Load(); m != nil {
+		return *m
+	}
+	return ""
+}
+
+// outputTargets splits s into redshift calls. Unless another method is
+// pinned we force the X11 one, which avoids the Wayland probe. On a
+// multi-seat machine only our seat's CRTCs are addressed, one call each,
+// and with focus emphasis every CRTC gets its own call so the focused one
+// can differ. Other X screens get a call each.
+func (u *uiState) outputTargets(s Settings) []outputTarget {
+	if m := u.pinnedMethod(); m != "" && m != "randr" {
+		return []outputTarget{{m, s}}
+	}
+	if u.remote.Load() != nil { // we can't see the remote outputs
+		return []outputTarget{{"randr", s}}
+	}
+	screens, def := u.xScreens()
+	var targets []outputTarget
+	for _, n := range screens {
+		if n == def {
+			targets = append(targets, u.seatTargets(s)...)
+		} else {
+			targets = append(targets, outputTarget{"randr:screen=" + strconv.Itoa(n), s})
+		}
+	}
+	return targets
+}
+
+// seatTargets is outputTargets for the default X screen.
+func (u *uiState) seatTargets(s Settings) []outputTarget {
+	foreign := u.seat.foreignEDIDs()
+	if u.perOutput() {
+		if idx, err := seatCRTCIndexes(foreign); err == nil {
+			settingsFor := u.outputSettings()
+			targets := make([]outputTarget, len(idx))
+			for i, n := range idx {
+				targets[i] = outputTarget{"randr:crtc=" + strconv.Itoa(n), settingsFor(n, s)}
+			}
+			return targets
+		}
+	}
+	methods := seatMethods(foreign)
+	if methods == nil {
+		methods = []string{"randr"}
+	}
+	targets := make([]outputTarget, len(methods))
+	for i, m := range methods {
+		targets[i] = outputTarget{m, s}
+	}
+	return targets
+}
+
+// rampBackend computes the ramps itself and writes them over RandR, on
+// top of any base correction, without spawning anything: a slider change
+// costs a few X requests instead of a redshift process. It is also what
+// backendFor falls back to.
+type rampBackend struct {
+	u      *uiState
+	status atomic.Pointer[string]
+	probe  sync.Once
+	usable bool
+}
+
+func (b *rampBackend) Name() string { return backendRamps }
+
+// Available probes the X server once: a CRTC with a gamma table means
+// the ramps can be set.
+func (b *rampBackend) Available() bool {
+	b.probe.Do(func() {
+		sizes, err := seatGammaSizes(nil)
+		b.usable = !waylandSession() && err == nil && len(sizes) > 0
+	})
+	return b.usable
+}
+
+func (b *rampBackend) Capabilities() Capabilities {
+	return Capabilities{Temperature: true, Brightness: true, Gamma: true, ChannelGamma: true, Curves: true, WhitePoint: true, PerOutput: true}
+}
+
+// Limits: the ramps can dim further than redshift lets them.
+func (b *rampBackend) Limits() Ranges {
+	l := redshiftLimits
+	l.BrightnessMin = 0.02
+	return l
+}
+
+func (b *rampBackend) Apply(_ context.Context, s Settings) error {
+	if b.u.remote.Load() != nil {
+		return errors.New("native ramps only reach this machine's display")
+	}
+	msg, err := b.u.applyRamps(s, b.u.base.Load())
+	if err != nil {
+		return err
+	}
+	b.status.Store(&msg)
+	return nil
+}
+
+func (b *rampBackend) Reset(ctx context.Context) error {
+	return b.Apply(ctx, defaultSettings)
+}
+
+func (b *rampBackend) Status() string {
+	if msg := b.status.Load(); msg != nil {
+		return *msg
+	}
+	return "Applied."
+}
+
+// backendStatus says which backend is in use and whether it was
+// detected, for the status line.
+func (u *uiState) backendStatus() string {
+	b := u.backend()
+	switch {
+	case u.cfg.Backend == "" && !available(b):
+		return "No working backend found. Install redshift, gammastep, xsct or xrandr, or pick one from the menu."
+	case u.cfg.Backend == "":
+		return "Using " + b.Name() + " (detected)."
+	case !available(b):
+		return fmt.Sprintf("Error: the %s backend can't run here. Install it or pick another backend from the menu.", b.Name())
+	}
+	return "Using " + b.Name() + "."
+}
+
+// backendMenu offers the available backends, the chosen one checked.
+func (u *uiState) backendMenu() *fyne.Menu {
+	auto := fyne.NewMenuItem("Detect automatically", func() { u.setBackend("") })
+	if u.cfg.Backend == "" {
+		auto.Label += " (" + u.backend().Name() + ")"
+	}
+	auto.Checked = u.cfg.Backend == ""
+	items := []*fyne.MenuItem{auto, fyne.NewMenuItemSeparator()}
+	for _, b := range u.backends {
+		if !available(b) && b != u.backend() {
+			continue
+		}
+		name := b.Name()
+		item := fyne.NewMenuItem(name, func() { u.setBackend(name) })
+		item.Checked = u.cfg.Backend == name
+		items = append(items, item)
+	}
+	items = append(items, fyne.NewMenuItemSeparator(), fyne.NewMenuItem("Preferences…", u.showBackendPrefs))
+	return fyne.NewMenu("", items...)
+}
+
+// adjustmentMethods are the -m methods redshift and gammastep share.
+var adjustmentMethods = []string{"randr", "vidmode", "drm", "wayland"}
+
+// showBackendPrefs lets the user pin a backend and, for redshift and
+// gammastep, an adjustment method instead of having them detected, and
+// write the custom backend's command.
+func (u *uiState) showBackendPrefs() {
+	const auto = "Detect automatically"
+	names := []string{auto}
+	for _, b := range u.backends {
+		names = append(names, b.Name())
+	}
+	backend := widget.NewSelect(names, nil)
+	backend.SetSelected(auto)
+	if u.cfg.Backend != "" {
+		backend.SetSelected(u.cfg.Backend)
+	}
+	method := widget.NewSelect(append([]string{auto}, adjustmentMethods...), nil)
+	method.SetSelected(auto)
+	if u.cfg.Method != "" {
+		method.SetSelected(u.cfg.Method)
+	}
+	custom := widget.NewEntry()
+	custom.SetPlaceHolder("mytool --kelvin {temp} --level {brightness}")
+	custom.SetText(u.cfg.CustomCommand)
+	customItem := widget.NewFormItem("Custom command", custom)
+	customItem.HintText = "For the custom backend. {temp}, {brightness} and {gamma} are filled in."
+	dialog.ShowForm("Backend", "Save", "Cancel", []*widget.FormItem{
+		widget.NewFormItem("Backend", backend),
+		widget.NewFormItem("Method", method),
+		customItem,
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+		m := method.Selected
+		if m == auto {
+			m = ""
+		}
+		u.cfg.Method = m
+		u.method.Store(&m)
+		c := strings.TrimSpace(custom.Text)
+		u.cfg.CustomCommand = c
+		u.custom.Store(&c)
+		name := backend.Selected
+		if name == auto {
+			name = ""
+		}
+		u.setBackend(name) // saves the config and reapplies
+	}, u.win)
+}
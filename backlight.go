@@ -0,0 +1,152 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"github.com/godbus/dbus/v5"
+)
+
+const backlightDir = "/sys/class/backlight"
+
+// backlight is a kernel backlight device such as intel_backlight.
+type backlight struct {
+	name string
+	max  int
+}
+
+// findBacklight returns the first backlight device, preferring firmware
+// and platform interfaces over raw ones as the kernel documentation asks.
+func findBacklight() (backlight, bool) {
+	entries, err := os.ReadDir(backlightDir)
+	if err != nil || len(entries) == 0 {
+		return backlight{}, false
+	}
+	best, bestRank := "", -1
+	rank := map[string]int{"raw": 0, "platform": 1, "firmware": 2}
+	for _, e := range entries {
+		typ, _ := os.ReadFile(filepath.Join(backlightDir, e.Name(), "type"))
+		if r := rank[strings.TrimSpace(string(typ))]; r > bestRank {
+			best, bestRank = e.Name(), r
+		}
+	}
+	max, err := readSysInt(filepath.Join(backlightDir, best, "max_brightness"))
+	if err != nil || max <= 0 {
+		return backlight{}, false
+	}
+	return backlight{name: best, max: max}, true
+}
+
+func (b backlight) get() (int, error) {
+	return readSysInt(filepath.Join(backlightDir, b.name, "brightness"))
+}
+
+// set changes the level through logind, which lets the active session do
+// so without root; writing sysfs directly, then brightnessctl (often
+// setuid or in the video group's udev rules) are the fallbacks.
+func (b backlight) set(level int) error {
+	level = min(max(level, 0), b.max)
+	conn, err := dbus.SystemBus()
+	if err == nil {
+		err = conn.Object("org.freedesktop.login1", "/org/freedesktop/login1/session/auto").
+			Call("org.freedesktop.login1.Session.SetBrightness", 0, "backlight", b.name, uint32(level)).Err
+		if err == nil {
+			return nil
+		}
+	}
+	werr := os.WriteFile(filepath.Join(backlightDir, b.name, "brightness"), []byte(strconv.Itoa(level)), 0o644)
+	if werr == nil {
+		return nil
+	}
+	name, args := hostCommand("brightnessctl", "-d", b.name, "set", strconv.Itoa(level))
+	out, cerr := exec.Command(name, args...).CombinedOutput()
+	if cerr != nil {
+		return errors.Join(err, werr, commandError(out, cerr))
+	}
+	return nil
+}
+
+// floor is the lowest level we drive the panel to; many panels switch off
+// completely at zero.
+func (b backlight) floor() int {
+	return max(1, b.max/20)
+}
+
+func readSysInt(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+// backlightState is the panel found for Config.Backlight, the level last
+// set, and the level it had before we took over.
+type backlightState struct {
+	mu     sync.Mutex
+	dev    *backlight // nil until found
+	last   int
+	before int
+}
+
+// applyBacklight sets the laptop panel to its share of s.Brightness and
+// returns s with the share left for the gamma. Only this machine's panel
+// can be reached.
+func (u *uiState) applyBacklight(s Settings) (Settings, string) {
+	mode := hardwareOff
+	if m := u.blMode.Load(); m != nil {
+		mode = *m
+	}
+	if mode == hardwareOff || u.remote.Load() != nil {
+		return s, ""
+	}
+	st := &u.bl
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.dev == nil {
+		bl, ok := findBacklight()
+		if !ok {
+			return s, " No laptop backlight found."
+		}
+		st.dev, st.last = &bl, -1
+		st.before, _ = bl.get()
+	}
+	bl := *st.dev
+	panel, gamma := hardwareSplit(mode, s.Brightness, float64(bl.floor())/float64(bl.max))
+	s.Brightness = gamma
+	level := max(int(math.Round(panel*float64(bl.max))), bl.floor())
+	if level == st.last {
+		return s, ""
+	}
+	if err := bl.set(level); err != nil {
+		return s, " Backlight error: " + err.Error() + "."
+	}
+	st.last = level
+	return s, ""
+}
+
+// setBacklightMode picks how the Brightness slider uses the laptop
+// backlight. Leaving it puts the panel back where it was.
+func (u *uiState) setBacklightMode(mode string) {
+	u.cfg.Backlight = mode
+	u.saveConfig()
+	u.blMode.Store(&mode)
+	u.syncControls()
+	go func() {
+		st := &u.bl
+		st.mu.Lock()
+		if st.dev != nil && mode == hardwareOff && st.before > 0 {
+			st.dev.set(st.before)
+		}
+		st.dev = nil
+		st.mu.Unlock()
+		fyne.Do(func() { u.scheduleApply(u.target()) })
+	}()
+}
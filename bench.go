@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const benchRuns = 20
+
+// benchBackends returns the backends that can run here.
+func (u *uiState) benchBackends() []Backend {
+	var backends []Backend
+	for _, b := range u.backends {
+		if available(b) && (u.remote.Load() == nil || b.Capabilities().Remote) {
+			backends = append(backends, b)
+		}
+	}
+	return backends
+}
+
+// benchResult holds one backend's timings, sorted.
+type benchResult struct {
+	name  string
+	times []time.Duration
+	err   error
+}
+
+func (r benchResult) percentile(p float64) time.Duration {
+	if len(r.times) == 0 {
+		return 0
+	}
+	return r.times[min(int(p*float64(len(r.times))), len(r.times)-1)]
+}
+
+func (r benchResult) String() string {
+	if r.err != nil {
+		return fmt.Sprintf("%-24s failed: %v", r.name, r.err)
+	}
+	ms := func(d time.Duration) string { return formatNum("%6.1f", float64(d.Microseconds())/1000) }
+	return fmt.Sprintf("%-24s %s %s %s %s", r.name,
+		ms(r.percentile(0.50)), ms(r.percentile(0.90)), ms(r.percentile(0.99)), ms(r.times[len(r.times)-1]))
+}
+
+// runBenchmark alternates each backend between two nearby states n times,
+// the way a slider drag would, and times every apply end to end.
+func (u *uiState) runBenchmark(backends []Backend, from Settings, n int) []benchResult {
+	to := from
+	to.TempK = max(from.TempK-100, 1000)
+	results := make([]benchResult, len(backends))
+	for i, b := range backends {
+		results[i].name = b.Name()
+		for run := 0; run < n; run++ {
+			s := from
+			if run%2 == 1 {
+				s = to
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			start := time.Now()
+			err := b.Apply(ctx, s)
+			d := time.Since(start)
+			cancel()
+			u.stopDither() // as the apply pipeline does before the next one
+			if err != nil {
+				results[i].err = err
+				break
+			}
+			results[i].times = append(results[i].times, d)
+		}
+		slices.Sort(results[i].times)
+	}
+	return results
+}
+
+// showBenchmark runs the benchmark in the background and shows the
+// percentiles, then puts the real settings back.
+func (u *uiState) showBenchmark() {
+	backends := u.benchBackends()
+	from, _ := u.validate(u.target())
+	u.out.SetText(fmt.Sprintf("Benchmarking %d backends…", len(backends)))
+	go func() {
+		results := u.runBenchmark(backends, from, benchRuns)
+		fyne.Do(func() {
+			lines := []string{fmt.Sprintf("%-24s %6s %6s %6s %6s   (ms, %d runs)", "", "p50", "p90", "p99", "max", benchRuns)}
+			for _, r := range results {
+				lines = append(lines, r.String())
+			}
+			text := widget.NewLabel(strings.Join(lines, "\n"))
+			text.TextStyle = fyne.TextStyle{Monospace: true}
+			dialog.ShowCustom("Apply latency", "Close", text, u.win)
+			u.out.SetText("Benchmark done.")
+			u.scheduleApply(u.target())
+		})
+	}()
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+const (
+	breakOverride = "Break"
+	breakDimBy    = 0.6 // brightness factor while a break dims the screen
+)
+
+// setBreaks starts or stops the 20-20-20 reminders and persists the choice:
+// every BreakEveryMinutes, look 20 feet away for BreakSeconds.
+func (u *uiState) setBreaks(on bool) {
+	if on == (u.breakStop != nil) {
+		return
+	}
+	u.cfg.Breaks = on
+	u.saveConfig()
+
+	if !on {
+		close(u.breakStop)
+		u.breakStop = nil
+		u.popOverride(breakOverride)
+		return
+	}
+	stop := make(chan struct{})
+	u.breakStop = stop
+	go func() {
+		tick := time.NewTicker(time.Duration(u.cfg.BreakEveryMinutes) * time.Minute)
+		defer tick.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-tick.C:
+				fyne.Do(func() {
+					if u.breakStop == stop {
+						u.takeBreak()
+					}
+				})
+			}
+		}
+	}()
+}
+
+func (u *uiState) setBreakDim(on bool) {
+	u.cfg.BreakDim = on
+	u.saveConfig()
+}
+
+func (u *uiState) takeBreak() {
+	fyne.CurrentApp().SendNotification(fyne.NewNotification("Time for an eye break",
+		fmt.Sprintf("Look at something about 20 feet (6 m) away for %d seconds.", u.cfg.BreakSeconds)))
+	if !u.cfg.BreakDim {
+		return
+	}
+	s := u.target()
+	s.Brightness = math.Max(s.Brightness*breakDimBy, 0.10)
+	u.pushTimedOverride(breakOverride, s, time.Duration(u.cfg.BreakSeconds)*time.Second)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/jezek/xgb/xproto"
+)
+
+const (
+	keysymBrightnessUp   xproto.Keysym = 0x1008FF02 // XF86MonBrightnessUp
+	keysymBrightnessDown xproto.Keysym = 0x1008FF03 // XF86MonBrightnessDown
+	brightnessKeySteps                 = 20         // key presses from 0 to full
+)
+
+func (u *uiState) setBrightnessKeys(on bool) {
+	u.cfg.BrightnessKeys = on
+	u.saveConfig()
+	u.syncHotkeys()
+}
+
+func (u *uiState) brightnessHotkeys() []hotkey {
+	if !u.cfg.BrightnessKeys {
+		return nil
+	}
+	return []hotkey{
+		{keysym: keysymBrightnessUp, fire: func() { u.stepBrightness(+1) }},
+		{keysym: keysymBrightnessDown, fire: func() { u.stepBrightness(-1) }},
+	}
+}
+
+// stepBrightness treats the backlight and gamma brightness as one range:
+// going down, the backlight is drained to its floor first and gamma takes
+// over from there; going up, gamma is restored to full before the
+// backlight is raised again. Without a backlight only gamma moves.
+func (u *uiState) stepBrightness(dir int) {
+	if u.cfg.Backlight != hardwareOff { // the slider already drives the backlight
+		u.brightness.nudge(dir * stepsPer(u.brightness, 1.0/brightnessKeySteps))
+		return
+	}
+	bl, hasBL := findBacklight()
+	level := 0
+	if hasBL {
+		var err error
+		if level, err = bl.get(); err != nil {
+			hasBL = false
+		}
+	}
+	gamma := u.brightness.Value()
+	useBL := hasBL && (dir > 0 && gamma >= u.brightness.Slider.Max || dir < 0 && level > bl.floor())
+
+	if useBL {
+		step := max(1, bl.max/brightnessKeySteps)
+		level = max(level+dir*step, bl.floor())
+		if err := bl.set(level); err != nil {
+			u.out.SetText("Backlight error: " + err.Error())
+			return
+		}
+		u.out.SetText(fmt.Sprintf("Backlight %d%%", level*100/bl.max))
+		return
+	}
+	u.brightness.nudge(dir * stepsPer(u.brightness, 1.0/brightnessKeySteps)) // applies via the slider handler
+}
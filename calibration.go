@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"math/bits"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+)
+
+// exportRampSize matches the 256-entry tables most X drivers expose; it
+// is used when the real table size can't be read.
+const exportRampSize = 256
+
+var rampFileFilter = storage.NewExtensionFileFilter([]string{".csv", ".icc", ".icm"})
+
+// baseFileFilter adds the ArgyllCMS calibration and measurement files.
+var baseFileFilter = storage.NewExtensionFileFilter([]string{".csv", ".icc", ".icm", ".cal", ".ti3"})
+
+// exportRamps saves the ramps for the current slider values, including any
+// imported base correction, as CSV or as an ICC profile's vcgt tag.
+func (u *uiState) exportRamps() {
+	size := exportRampSize
+	if u.remote.Load() == nil {
+		if sizes, err := seatGammaSizes(u.seat.foreignEDIDs()); err == nil && len(sizes) > 0 {
+			size = slices.Max(sizes) // keep the precision of 10- and 12-bit tables
+		}
+	}
+	r := computeRampRef(u.target(), u.base.Load(), refCurve(u.reference.Load()), size)
+
+	d := dialog.NewFileSave(func(wc fyne.URIWriteCloser, err error) {
+		if err != nil || wc == nil {
+			return
+		}
+		defer wc.Close()
+		if err := writeRampFile(wc.URI().Name(), wc, r); err != nil {
+			u.out.SetText("Export error: " + err.Error())
+			return
+		}
+		u.out.SetText("Exported ramps to " + wc.URI().Name() + ".")
+	}, u.win)
+	d.SetFileName("ramps.csv")
+	d.SetFilter(rampFileFilter)
+	d.Show()
+}
+
+// importBase loads a ramp file as the base correction the sliders are
+// applied on top of. While a base is loaded the ramps are written directly
+// over RandR, since redshift has no way to take a starting curve.
+func (u *uiState) importBase() {
+	d := dialog.NewFileOpen(func(rc fyne.URIReadCloser, err error) {
+		if err != nil || rc == nil {
+			return
+		}
+		defer rc.Close()
+		if isArgyllFile(rc.URI().Name()) {
+			u.importArgyll(rc)
+			return
+		}
+		r, err := readRampFile(rc.URI().Name(), rc)
+		if err != nil {
+			u.out.SetText("Import error: " + err.Error())
+			return
+		}
+		u.base.Store(&r)
+		u.out.SetText(fmt.Sprintf("Loaded base correction from %s (%d entries).", rc.URI().Name(), r.Size()))
+		u.scheduleApply(u.target())
+	}, u.win)
+	d.SetFilter(baseFileFilter)
+	d.Show()
+}
+
+// importArgyll loads an ArgyllCMS .cal or .ti3 as the base: its curves,
+// corrected for the measured white point if the file has one.
+func (u *uiState) importArgyll(rc fyne.URIReadCloser) {
+	m, err := readArgyll(rc)
+	if err != nil {
+		u.out.SetText("Import error: " + err.Error())
+		return
+	}
+	r := panelBase(m.curves, m.whiteX, m.whiteY)
+	u.base.Store(&r)
+	var got []string
+	if m.curves != nil {
+		got = append(got, fmt.Sprintf("%d-entry curves", m.curves.Size()))
+	}
+	if m.whiteX != 0 {
+		got = append(got, fmt.Sprintf("measured white x %s, y %s", formatNum("%.4f", m.whiteX), formatNum("%.4f", m.whiteY)))
+	}
+	u.out.SetText(fmt.Sprintf("Loaded %s from %s.", strings.Join(got, " and "), rc.URI().Name()))
+	u.scheduleApply(u.target())
+}
+
+func (u *uiState) clearBase() {
+	if u.base.Swap(nil) == nil {
+		return
+	}
+	u.out.SetText("Cleared base correction.")
+	u.scheduleApply(u.target())
+}
+
+// applyRamps writes s on top of base, which may be nil, straight to the
+// CRTCs. Each CRTC gets a table of its own size, so panels with 10- or
+// 12-bit gamma tables get the finer steps rather than an 8-bit ramp
+// stretched over them. It returns the status message.
+func (u *uiState) applyRamps(s Settings, base *Ramp) (string, error) {
+	settingsFor := u.outputSettings()
+	ref := refCurve(u.reference.Load())
+	var largest int
+	rampFor := func(crtc, size int) Ramp {
+		largest = max(largest, size)
+		return computeRampRef(settingsFor(crtc, s), base, ref, size)
+	}
+	screens, _ := u.xScreens()
+	err := setRandrRamps(u.seat.foreignEDIDs(), screens, rampFor)
+	if err != nil {
+		return "", err
+	}
+	how := "native ramps"
+	if base != nil {
+		how = "with base correction"
+	}
+	if u.dither.Load() && needsDither(s) {
+		u.ditherMu.Lock()
+		u.stopDitherLocked() // an overlapping apply may have started one since ours stopped it
+		u.ditherStop = make(chan struct{})
+		u.ditherDone = startDither(u.seat.foreignEDIDs(), screens, rampFor, u.ditherStop)
+		u.ditherMu.Unlock()
+		return fmt.Sprintf("Applied (%s, %d-bit, dithered).", how, rampBits(largest)), nil
+	}
+	return fmt.Sprintf("Applied (%s, %d-bit).", how, rampBits(largest)), nil
+}
+
+// stopDither ends a running dither cycle and waits for its last frame.
+// Safe to call from any goroutine.
+func (u *uiState) stopDither() {
+	u.ditherMu.Lock()
+	defer u.ditherMu.Unlock()
+	u.stopDitherLocked()
+}
+
+// stopDitherLocked is stopDither for callers holding ditherMu.
+func (u *uiState) stopDitherLocked() {
+	if u.ditherStop != nil {
+		close(u.ditherStop)
+		<-u.ditherDone
+		u.ditherStop, u.ditherDone = nil, nil
+	}
+}
+
+// setDither turns temporal dithering of the native ramps on or off.
+func (u *uiState) setDither(on bool) {
+	u.cfg.Dither = on
+	u.saveConfig()
+	u.dither.Store(on)
+	u.scheduleApply(u.target())
+}
+
+// rampBits is the input depth a gamma table of size entries resolves.
+func rampBits(size int) int {
+	return bits.Len(uint(max(size, 1) - 1))
+}
+
+// setReference picks the reference transfer function.
+func (u *uiState) setReference(ref refCurve) {
+	u.cfg.Reference = refCurveNames[ref]
+	u.saveConfig()
+	u.reference.Store(int32(ref))
+	u.scheduleApply(u.target())
+}
+
+const referenceOverride = "Reference"
+
+// toggleNeutralReference shows exactly the reference curve, with no
+// temperature or dimming, until toggled again or a slider moves.
+func (u *uiState) toggleNeutralReference() {
+	if u.hasOverride(referenceOverride) {
+		u.popOverride(referenceOverride)
+		return
+	}
+	u.pushOverride(referenceOverride, defaultSettings)
+}
+
+// referenceMenu offers the reference curves with the chosen one checked.
+func (u *uiState) referenceMenu() *fyne.Menu {
+	cur := refCurve(u.reference.Load())
+	item := func(label string, ref refCurve) *fyne.MenuItem {
+		it := fyne.NewMenuItem(label, func() { u.setReference(ref) })
+		it.Checked = cur == ref
+		return it
+	}
+	neutral := fyne.NewMenuItem("Show neutral reference", u.toggleNeutralReference)
+	neutral.Checked = u.hasOverride(referenceOverride)
+	return fyne.NewMenu("Display reference",
+		item("None (redshift-style)", refNone),
+		item("sRGB", refSRGB),
+		item("Gamma 2.2", refGamma22),
+		fyne.NewMenuItemSeparator(),
+		neutral,
+	)
+}
@@ -0,0 +1,117 @@
+package main
+
+// city is an entry in the offline city list for picking a location
+// without a network. Coordinates are to a hundredth of a degree, finer
+// than anything we store.
+type city struct {
+	name string
+	loc  Location
+}
+
+// cities lists capitals and other large cities, roughly one per
+// timezone-sized region, sorted by name.
+var cities = []city{
+	{"Abu Dhabi, United Arab Emirates", Location{24.45, 54.38}},
+	{"Accra, Ghana", Location{5.60, -0.19}},
+	{"Addis Ababa, Ethiopia", Location{9.03, 38.74}},
+	{"Adelaide, Australia", Location{-34.93, 138.60}},
+	{"Algiers, Algeria", Location{36.75, 3.06}},
+	{"Amsterdam, Netherlands", Location{52.37, 4.90}},
+	{"Anchorage, United States", Location{61.22, -149.90}},
+	{"Ankara, Turkey", Location{39.93, 32.86}},
+	{"Athens, Greece", Location{37.98, 23.73}},
+	{"Atlanta, United States", Location{33.75, -84.39}},
+	{"Auckland, New Zealand", Location{-36.85, 174.76}},
+	{"Baghdad, Iraq", Location{33.31, 44.37}},
+	{"Bangkok, Thailand", Location{13.76, 100.50}},
+	{"Barcelona, Spain", Location{41.39, 2.17}},
+	{"Beijing, China", Location{39.90, 116.41}},
+	{"Beirut, Lebanon", Location{33.89, 35.50}},
+	{"Belgrade, Serbia", Location{44.79, 20.45}},
+	{"Berlin, Germany", Location{52.52, 13.40}},
+	{"Bogotá, Colombia", Location{4.71, -74.07}},
+	{"Boston, United States", Location{42.36, -71.06}},
+	{"Brasília, Brazil", Location{-15.79, -47.88}},
+	{"Brisbane, Australia", Location{-27.47, 153.03}},
+	{"Brussels, Belgium", Location{50.85, 4.35}},
+	{"Bucharest, Romania", Location{44.43, 26.10}},
+	{"Budapest, Hungary", Location{47.50, 19.04}},
+	{"Buenos Aires, Argentina", Location{-34.60, -58.38}},
+	{"Cairo, Egypt", Location{30.04, 31.24}},
+	{"Cape Town, South Africa", Location{-33.92, 18.42}},
+	{"Caracas, Venezuela", Location{10.48, -66.90}},
+	{"Casablanca, Morocco", Location{33.57, -7.59}},
+	{"Chicago, United States", Location{41.88, -87.63}},
+	{"Copenhagen, Denmark", Location{55.68, 12.57}},
+	{"Dakar, Senegal", Location{14.72, -17.47}},
+	{"Dallas, United States", Location{32.78, -96.80}},
+	{"Delhi, India", Location{28.61, 77.21}},
+	{"Denver, United States", Location{39.74, -104.99}},
+	{"Dhaka, Bangladesh", Location{23.81, 90.41}},
+	{"Dubai, United Arab Emirates", Location{25.20, 55.27}},
+	{"Dublin, Ireland", Location{53.35, -6.26}},
+	{"Edinburgh, United Kingdom", Location{55.95, -3.19}},
+	{"Helsinki, Finland", Location{60.17, 24.94}},
+	{"Ho Chi Minh City, Vietnam", Location{10.82, 106.63}},
+	{"Hong Kong, China", Location{22.32, 114.17}},
+	{"Honolulu, United States", Location{21.31, -157.86}},
+	{"Houston, United States", Location{29.76, -95.37}},
+	{"Istanbul, Turkey", Location{41.01, 28.98}},
+	{"Jakarta, Indonesia", Location{-6.21, 106.85}},
+	{"Johannesburg, South Africa", Location{-26.20, 28.05}},
+	{"Karachi, Pakistan", Location{24.86, 67.01}},
+	{"Kathmandu, Nepal", Location{27.72, 85.32}},
+	{"Kinshasa, DR Congo", Location{-4.44, 15.27}},
+	{"Kuala Lumpur, Malaysia", Location{3.14, 101.69}},
+	{"Kyiv, Ukraine", Location{50.45, 30.52}},
+	{"Lagos, Nigeria", Location{6.52, 3.38}},
+	{"Lima, Peru", Location{-12.05, -77.04}},
+	{"Lisbon, Portugal", Location{38.72, -9.14}},
+	{"London, United Kingdom", Location{51.51, -0.13}},
+	{"Los Angeles, United States", Location{34.05, -118.24}},
+	{"Madrid, Spain", Location{40.42, -3.70}},
+	{"Manila, Philippines", Location{14.60, 120.98}},
+	{"Melbourne, Australia", Location{-37.81, 144.96}},
+	{"Mexico City, Mexico", Location{19.43, -99.13}},
+	{"Miami, United States", Location{25.76, -80.19}},
+	{"Milan, Italy", Location{45.46, 9.19}},
+	{"Montevideo, Uruguay", Location{-34.90, -56.16}},
+	{"Montreal, Canada", Location{45.50, -73.57}},
+	{"Moscow, Russia", Location{55.76, 37.62}},
+	{"Mumbai, India", Location{19.08, 72.88}},
+	{"Munich, Germany", Location{48.14, 11.58}},
+	{"Nairobi, Kenya", Location{-1.29, 36.82}},
+	{"New York, United States", Location{40.71, -74.01}},
+	{"Oslo, Norway", Location{59.91, 10.75}},
+	{"Ottawa, Canada", Location{45.42, -75.70}},
+	{"Paris, France", Location{48.86, 2.35}},
+	{"Perth, Australia", Location{-31.95, 115.86}},
+	{"Prague, Czechia", Location{50.08, 14.44}},
+	{"Reykjavík, Iceland", Location{64.15, -21.94}},
+	{"Riga, Latvia", Location{56.95, 24.11}},
+	{"Rio de Janeiro, Brazil", Location{-22.91, -43.17}},
+	{"Riyadh, Saudi Arabia", Location{24.71, 46.68}},
+	{"Rome, Italy", Location{41.90, 12.50}},
+	{"San Francisco, United States", Location{37.77, -122.42}},
+	{"Santiago, Chile", Location{-33.45, -70.67}},
+	{"São Paulo, Brazil", Location{-23.55, -46.63}},
+	{"Seattle, United States", Location{47.61, -122.33}},
+	{"Seoul, South Korea", Location{37.57, 126.98}},
+	{"Shanghai, China", Location{31.23, 121.47}},
+	{"Singapore", Location{1.35, 103.82}},
+	{"Stockholm, Sweden", Location{59.33, 18.07}},
+	{"Sydney, Australia", Location{-33.87, 151.21}},
+	{"Taipei, Taiwan", Location{25.03, 121.57}},
+	{"Tallinn, Estonia", Location{59.44, 24.75}},
+	{"Tehran, Iran", Location{35.69, 51.39}},
+	{"Tel Aviv, Israel", Location{32.09, 34.78}},
+	{"Tokyo, Japan", Location{35.68, 139.69}},
+	{"Toronto, Canada", Location{43.65, -79.38}},
+	{"Tromsø, Norway", Location{69.65, 18.96}},
+	{"Vancouver, Canada", Location{49.28, -123.12}},
+	{"Vienna, Austria", Location{48.21, 16.37}},
+	{"Vilnius, Lithuania", Location{54.69, 25.28}},
+	{"Warsaw, Poland", Location{52.23, 21.01}},
+	{"Wellington, New Zealand", Location{-41.29, 174.78}},
+	{"Zurich, Switzerland", Location{47.38, 8.54}},
+}
@@ -0,0 +1,507 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// configVersion is the schema Config is saved in; see migrated.
+const configVersion = 2
+
+// Config is everything persisted between runs.
+type Config struct {
+	Version int `json:"version"` // schema of the file, 0 before versioning
+
+	Backend       string  `json:"backend,omitempty"`        // a Backend name, "" to pick by session
+	Method        string  `json:"method,omitempty"`         // redshift/gammastep -m method, "" to pick
+	CustomCommand string  `json:"custom_command,omitempty"` // sh -c template for the custom backend
+	Remote        *Remote `json:"remote,omitempty"`         // nil drives this machine's screen
+	XScreen       *int    `json:"x_screen,omitempty"`       // X screen to drive, nil for all
+	Dither        bool    `json:"dither"`                   // temporal dithering of native ramps
+	Reference     string  `json:"reference,omitempty"`      // "srgb" or "gamma22" to adjust in linear light
+	DDC           string  `json:"ddc,omitempty"`            // monitor brightness over DDC/CI: "", "instead" or "also"
+	Backlight     string  `json:"backlight,omitempty"`      // laptop panel brightness, same modes as DDC
+
+	KeyboardDim    bool    `json:"keyboard_dim"`     // dim the keyboard backlight at night
+	KeyboardNightK int     `json:"keyboard_night_k"` // at or below this temperature
+	KeyboardLevel  float64 `json:"keyboard_level"`   // fraction of full, 0 turns it off
+
+	Remotes      []Remote            `json:"remotes,omitempty"`       // saved remote screens
+	HostSettings map[string]Settings `json:"host_settings,omitempty"` // last sliders per host, "" = local
+
+	ReapplyOnLaunch bool `json:"reapply_on_launch"` // put HostSettings back on screen at startup
+
+	Adaptive    bool     `json:"adaptive"`   // content-adaptive nudging
+	MovieMode   bool     `json:"movie_mode"` // switch to MoviePreset during playback
+	MoviePreset Settings `json:"movie_preset"`
+
+	RespectInhibitors bool `json:"respect_inhibitors"` // neutral while an app inhibits idle
+
+	GamingBoost   float64 `json:"gaming_boost"`   // brightness added in Gaming mode
+	GamingMinutes int     `json:"gaming_minutes"` // auto-restore after this long, 0 = never
+
+	ReadingPreset  Settings `json:"reading_preset"`
+	ReadingMinutes int      `json:"reading_minutes"`
+
+	WindDownPreset Settings `json:"wind_down_preset"` // where Wind down ends
+	WorkPreset     Settings `json:"work_preset"`      // the Work activity profile
+
+	Breaks            bool `json:"breaks"`    // 20-20-20 reminders
+	BreakDim          bool `json:"break_dim"` // dim the screen during a break
+	BreakEveryMinutes int  `json:"break_every_minutes"`
+	BreakSeconds      int  `json:"break_seconds"`
+
+	PomodoroFocusMinutes int     `json:"pomodoro_focus_minutes"`
+	PomodoroBreakMinutes int     `json:"pomodoro_break_minutes"`
+	PomodoroWarmK        int     `json:"pomodoro_warm_k"` // Kelvin taken off during breaks
+	PomodoroDim          float64 `json:"pomodoro_dim"`    // brightness factor during breaks
+
+	History       bool      `json:"history"` // record usage samples for statistics
+	WeeklySummary bool      `json:"weekly_summary"`
+	LastSummary   time.Time `json:"last_summary"`
+
+	Location          *Location `json:"location,omitempty"` // nil until one is known
+	LocationPrecision float64   `json:"location_precision"` // degrees, <= 0 keeps full precision
+	Places            []Place   `json:"places,omitempty"`
+	ActivePlace       string    `json:"active_place,omitempty"`
+	GeoClue           bool      `json:"geoclue"`         // Auto may ask GeoClue2 where we are
+	IPLocation        bool      `json:"ip_location"`     // failing that, look our IP address up online once
+	ManualLocation    bool      `json:"manual_location"` // Location was typed in; don't look it up
+
+	Auto      bool     `json:"auto"` // follow the sun between AutoDay and AutoNight
+	AutoDay   Settings `json:"auto_day"`
+	AutoNight Settings `json:"auto_night"`
+
+	// Following the sun, night fades in as it sinks from the high to the
+	// low elevation, in degrees.
+	AutoElevationHigh float64 `json:"auto_elevation_high"`
+	AutoElevationLow  float64 `json:"auto_elevation_low"`
+
+	// RedshiftDaemon has redshift's continual mode do Auto's job instead,
+	// for its own transitions; the sliders and overrides still take over
+	// for a while when used, see holdDaemon.
+	RedshiftDaemon bool `json:"redshift_daemon"`
+
+	// With AutoClock, Auto switches at set times rather than with the sun.
+	AutoClock       bool     `json:"auto_clock"`
+	AutoNightAt     TimeSpec `json:"auto_night_at"`
+	AutoDayAt       TimeSpec `json:"auto_day_at"`
+	AutoFadeMinutes int      `json:"auto_fade_minutes"`
+
+	Schedule        bool            `json:"schedule"` // follow ScheduleEntries
+	ScheduleEntries []ScheduleEntry `json:"schedule_entries"`
+	WeekendEntries  []ScheduleEntry `json:"weekend_schedule_entries,omitempty"` // Friday and Saturday nights, see weekSchedule; empty follows ScheduleEntries
+	ScheduleBlend   bool            `json:"schedule_blend"`                     // glide between entries instead of switching
+
+	CronRules []string `json:"cron_rules,omitempty"` // e.g. "0 22 * * 1-5 apply Bedtime"
+
+	WakeRamp    bool     `json:"wake_ramp"`           // sunrise simulation before alarms
+	WakeTime    string   `json:"wake_time,omitempty"` // "HH:MM"; "" follows GNOME Clocks
+	WakeMinutes int      `json:"wake_minutes"`
+	WakePreset  Settings `json:"wake_preset"` // where the ramp ends
+
+	Presets        []Preset          `json:"presets,omitempty"` // named, in the order saved
+	Slots          [slotCount]string `json:"slot_presets"`      // preset names on Super+Alt+1..9, "" for none
+	BrightnessKeys bool              `json:"brightness_keys"`   // grab XF86MonBrightnessUp/Down
+
+	OldSlots []*Preset `json:"slots,omitempty"` // schema 1 kept copies in the slots; see migrated
+
+	FileTriggers bool   `json:"file_triggers"`
+	TriggerDir   string `json:"trigger_dir,omitempty"` // "" means triggers/ next to this file
+
+	QuickActions []QuickAction `json:"quick_actions"`
+	Toolbar      []string      `json:"toolbar"`      // header actions by id, see toolbarActions
+	Compact      bool          `json:"compact"`      // hide the sliders, leaving header, profiles and status
+	FadeSeconds  int           `json:"fade_seconds"` // presets fade in over this long, 0 jumps
+	Steps        SliderSteps   `json:"steps"`
+	Ranges       Ranges        `json:"ranges"`
+	SafetyFloor  float64       `json:"safety_floor"` // brightness nothing may go below
+
+	LinkBrightness bool         `json:"link_brightness"` // temperature drags brightness along LinkCurve
+	LinkCurve      []CurvePoint `json:"link_curve"`
+
+	Outputs     map[string]OutputAdjust `json:"outputs,omitempty"` // per-monitor shifts by EDID identity, else output name
+	LinkOutputs bool                    `json:"link_outputs"`      // a drag in Monitors… moves every monitor
+
+	FocusEmphasis bool    `json:"focus_emphasis"` // other monitors a little dimmer and warmer
+	FocusDim      float64 `json:"focus_dim"`      // brightness taken off unfocused monitors
+	FocusWarmK    int     `json:"focus_warm_k"`   // Kelvin taken off unfocused monitors
+
+	WindowWidth  float32 `json:"window_width,omitempty"` // main window size when last closed
+	WindowHeight float32 `json:"window_height,omitempty"`
+
+	env map[string]envValue // keys set by applyEnv, kept out of the file
+}
+
+// envValue is one config key set from the environment: what the file had
+// and what the environment made of it.
+type envValue struct {
+	file, env json.RawMessage
+}
+
+func defaultConfig() Config {
+	return Config{
+		Version:           configVersion,
+		ReapplyOnLaunch:   true,
+		MoviePreset:       defaultSettings,
+		KeyboardNightK:    4000,
+		GamingBoost:       0.20,
+		GamingMinutes:     120,
+		ReadingPreset:     Settings{TempK: 4500, Brightness: 0.85, Gamma: 1.00},
+		ReadingMinutes:    45,
+		WindDownPreset:    Settings{TempK: 3000, Brightness: 0.70, Gamma: 1.00},
+		WorkPreset:        Settings{TempK: 5800, Brightness: 0.95, Gamma: 1.00, BlueReduction: 0.10},
+		BreakEveryMinutes: 20,
+		BreakSeconds:      20,
+
+		PomodoroFocusMinutes: 25,
+		PomodoroBreakMinutes: 5,
+		PomodoroWarmK:        500,
+		PomodoroDim:          0.85,
+
+		LocationPrecision: defaultLocationPrecision,
+		GeoClue:           true,
+		AutoDay:           defaultSettings,
+		AutoNight:         Settings{TempK: 3500, Brightness: 0.85, Gamma: 1.00},
+		AutoElevationHigh: defaultDayElevation,
+		AutoElevationLow:  defaultNightElevation,
+		AutoNightAt:       "21:00",
+		AutoDayAt:         "07:00",
+		AutoFadeMinutes:   30,
+		ScheduleEntries:   defaultSchedule,
+		WakeMinutes:       30,
+		WakePreset:        defaultSettings,
+
+		QuickActions: defaultQuickActions,
+		Toolbar:      defaultToolbar,
+		FadeSeconds:  2,
+		Steps:        defaultSliderSteps,
+		Ranges:       defaultRanges,
+		SafetyFloor:  0.10,
+
+		LinkCurve: defaultLinkCurve,
+
+		FocusDim:   0.15,
+		FocusWarmK: 300,
+	}
+}
+
+// configPath returns $XDG_CONFIG_HOME/redshift-control-panel/config.json.
+func configPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "redshift-control-panel", "config.json"), nil
+}
+
+// lockConfig takes the lock that keeps running instances from reading
+// the config while another replaces it. It doesn't merge: each instance
+// saves all of its own Config, so the last to save wins. It is a separate
+// file, as the config itself is replaced on every save. Call the returned
+// func to release it.
+func lockConfig(path string, exclusive bool) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f, exclusive); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() { f.Close() }, nil
+}
+
+// writeFileAtomic replaces path with data through a temporary file in the
+// same directory, so a crash or a concurrent reader never sees half of it.
+func writeFileAtomic(path string, data []byte, perm fs.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // fails harmlessly once renamed
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Chmod(perm); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// loadConfig reads the config file. A missing file is not an error; the
+// defaults are returned instead.
+func loadConfig() (Config, error) {
+	c := defaultConfig()
+	path, err := configPath()
+	if err != nil {
+		return c, err
+	}
+	unlock, err := lockConfig(path, false)
+	if err != nil {
+		return c, err
+	}
+	defer unlock()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	} else if err != nil {
+		return c, err
+	}
+	var newer error
+	if v := fileVersion(data); v > configVersion {
+		// Leave the newer file alone, see save, and carry on from our own
+		// copy if there is one yet.
+		side := sideConfigPath(path)
+		newer = fmt.Errorf("%s is from a newer version (schema %d); this one keeps its settings in %s", path, v, side)
+		if own, err := os.ReadFile(side); err == nil {
+			data = own
+		}
+	}
+	c.Version = 0 // a file without one predates versioning
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, err
+	}
+	return c.migrated().normalized(), newer
+}
+
+// fileVersion returns the schema a config file was saved in.
+func fileVersion(data []byte) int {
+	var v struct {
+		Version int `json:"version"`
+	}
+	json.Unmarshal(data, &v)
+	return v.Version
+}
+
+// sideConfigPath is where we save instead of path while path holds a
+// config from a newer version, whose additions we would otherwise drop.
+func sideConfigPath(path string) string {
+	return fmt.Sprintf("%s.v%d.json", strings.TrimSuffix(path, ".json"), configVersion)
+}
+
+// migrated brings a file saved in an older schema up to configVersion.
+func (c Config) migrated() Config {
+	// Version 1 only added the field itself. Later changes go here, each
+	// upgrading from the one before.
+	if c.Version < 2 {
+		// Slots held their own copies; they now name a preset. A named
+		// preset wins over a slot's copy of the same name.
+		for i, p := range c.OldSlots {
+			if p == nil || i >= slotCount {
+				continue
+			}
+			if !slices.ContainsFunc(c.Presets, func(q Preset) bool { return q.Name == p.Name }) {
+				c.Presets = append(c.Presets, *p)
+			}
+			c.Slots[i] = p.Name
+		}
+		c.OldSlots = nil
+	}
+	c.Version = configVersion
+	return c
+}
+
+// normalized re-applies the invariants a hand-edited file may break.
+func (c Config) normalized() Config {
+	if c.Remote != nil && !validHost(c.Remote.Host) {
+		c.Remote = nil
+	}
+	c.Remotes = slices.DeleteFunc(c.Remotes, func(r Remote) bool { return !validHost(r.Host) })
+	if c.Remote != nil && !slices.Contains(c.Remotes, *c.Remote) {
+		c.Remotes = append(c.Remotes, *c.Remote)
+	}
+	if c.Location != nil { // hand-edited files may be more precise than allowed
+		l := c.Location.coarse(c.LocationPrecision)
+		c.Location = &l
+	}
+	for i := range c.Places {
+		c.Places[i].Location = c.Places[i].Location.coarse(c.LocationPrecision)
+	}
+	if c.AutoElevationHigh <= c.AutoElevationLow {
+		c.AutoElevationHigh, c.AutoElevationLow = defaultDayElevation, defaultNightElevation
+	}
+	// A zero interval would panic the break ticker, a zero break never
+	// lift the dimming; zero pomodoro phases would flip back and forth.
+	c.BreakEveryMinutes = max(c.BreakEveryMinutes, 1)
+	c.BreakSeconds = max(c.BreakSeconds, 1)
+	c.PomodoroFocusMinutes = max(c.PomodoroFocusMinutes, 1)
+	c.PomodoroBreakMinutes = max(c.PomodoroBreakMinutes, 1)
+	return c
+}
+
+const envPrefix = "SCREENDIMMER_"
+
+// applyEnv overrides config keys from SCREENDIMMER_<KEY> variables, so
+// kiosk and container deployments can configure us without a file. KEY is
+// the JSON key upper-cased, e.g. SCREENDIMMER_GAMING_MINUTES=60. Values
+// are JSON, except that a bare word is taken as a string. The file keeps
+// its own values for these keys unless they are changed while running.
+func (c Config) applyEnv(environ []string) (Config, error) {
+	fields, err := c.fields()
+	if err != nil {
+		return c, err
+	}
+	file := maps.Clone(fields)
+	keys := configKeys()
+	set := map[string]bool{}
+	for _, kv := range environ {
+		name, val, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(name, envPrefix) {
+			continue
+		}
+		key := strings.ToLower(strings.TrimPrefix(name, envPrefix))
+		if !keys[key] {
+			return c, fmt.Errorf("%s: no config key %q", name, key)
+		}
+		if !json.Valid([]byte(val)) {
+			quoted, _ := json.Marshal(val)
+			val = string(quoted)
+		}
+		fields[key] = json.RawMessage(val)
+		set[key] = true
+	}
+	if len(set) == 0 {
+		return c, nil
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return c, err
+	}
+	var out Config
+	if err := json.Unmarshal(data, &out); err != nil {
+		return c, fmt.Errorf("environment: %w", err)
+	}
+	out = out.normalized()
+	got, err := out.fields()
+	if err != nil {
+		return c, err
+	}
+	out.env = map[string]envValue{}
+	for key := range set {
+		out.env[key] = envValue{file: file[key], env: got[key]}
+	}
+	return out, nil
+}
+
+// fields returns c as JSON, key by key.
+func (c Config) fields() (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	return fields, json.Unmarshal(data, &fields)
+}
+
+// withoutEnv returns c with the file's own values back in the keys the
+// environment set and that haven't changed since.
+func (c Config) withoutEnv() (Config, error) {
+	if len(c.env) == 0 {
+		return c, nil
+	}
+	fields, err := c.fields()
+	if err != nil {
+		return c, err
+	}
+	restore := map[string]json.RawMessage{}
+	for key, v := range c.env {
+		if !bytes.Equal(fields[key], v.env) {
+			continue // changed while running: that is the user's now
+		}
+		restore[key] = v.file
+		if v.file == nil {
+			restore[key] = json.RawMessage("null") // omitted, so zero
+		}
+	}
+	// Zero the fields first, so maps and pointers shared with c aren't
+	// written into.
+	rv := reflect.ValueOf(&c).Elem()
+	for i := range rv.NumField() {
+		if _, ok := restore[jsonKey(rv.Type().Field(i))]; ok {
+			rv.Field(i).SetZero()
+		}
+	}
+	data, err := json.Marshal(restore)
+	if err != nil {
+		return c, err
+	}
+	return c, json.Unmarshal(data, &c)
+}
+
+func (c Config) save() error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	if c, err = c.withoutEnv(); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	unlock, err := lockConfig(path, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if cur, err := os.ReadFile(path); err == nil && fileVersion(cur) > configVersion {
+		path = sideConfigPath(path)
+	}
+	return writeFileAtomic(path, data, 0o644)
+}
+
+// saveConfig persists u.cfg, reporting failures in the status line.
+func (u *uiState) saveConfig() {
+	if err := u.cfg.save(); err != nil {
+		u.out.SetText("Could not save settings: " + err.Error())
+	}
+}
+
+// saveWindowSize remembers the main window's size for the next launch.
+func (u *uiState) saveWindowSize() {
+	size := u.win.Canvas().Size()
+	u.cfg.WindowWidth, u.cfg.WindowHeight = size.Width, size.Height
+	u.saveConfig()
+}
+
+// configKeys returns the JSON key of every Config field.
+func configKeys() map[string]bool {
+	keys := map[string]bool{}
+	t := reflect.TypeOf(Config{})
+	for i := range t.NumField() {
+		if t.Field(i).IsExported() {
+			keys[jsonKey(t.Field(i))] = true
+		}
+	}
+	return keys
+}
+
+// jsonKey returns the JSON key of a Config field.
+func jsonKey(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	return name
+}
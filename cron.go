@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// cronSpec is a parsed five-field cron expression: minute, hour, day of
+// month, month and day of week, each a set of allowed values.
+type cronSpec struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool // field was "*"
+}
+
+var (
+	cronMonths = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	cronDays   = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+)
+
+// parseCronField parses one field, of comma-separated values, ranges
+// such as 1-5 and steps such as */15, into a bit per allowed value. names,
+// if any, are accepted for the values from lo on.
+func parseCronField(f string, lo, hi int, names []string) (uint64, error) {
+	num := func(s string) (int, error) {
+		for i, n := range names {
+			if strings.EqualFold(s, n) {
+				return lo + i, nil
+			}
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < lo || n > hi {
+			return 0, fmt.Errorf("%q is not in %d-%d", s, lo, hi)
+		}
+		return n, nil
+	}
+	var bits uint64
+	for _, part := range strings.Split(f, ",") {
+		rng, stepText, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepText); err != nil || step <= 0 {
+				return 0, fmt.Errorf("%q: bad step", part)
+			}
+		}
+		from, to := lo, hi
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			if from, err = num(a); err != nil {
+				return 0, err
+			}
+			to = from
+			if isRange {
+				if to, err = num(b); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				to = hi // "5/15" means from 5 on
+			}
+			if to < from {
+				return 0, fmt.Errorf("%q: range runs backwards", part)
+			}
+		}
+		for v := from; v <= to; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// parseCron parses "minute hour day-of-month month day-of-week". Day of
+// week runs from 0 for Sunday, and 7 is Sunday too.
+func parseCron(expr string) (cronSpec, error) {
+	f := strings.Fields(expr)
+	if len(f) != 5 {
+		return cronSpec{}, fmt.Errorf("%q: want 5 fields, have %d", expr, len(f))
+	}
+	var c cronSpec
+	var err error
+	for _, p := range []struct {
+		bits   *uint64
+		text   string
+		lo, hi int
+		names  []string
+	}{
+		{&c.minute, f[0], 0, 59, nil},
+		{&c.hour, f[1], 0, 23, nil},
+		{&c.dom, f[2], 1, 31, nil},
+		{&c.month, f[3], 1, 12, cronMonths},
+		{&c.dow, f[4], 0, 7, cronDays},
+	} {
+		if *p.bits, err = parseCronField(p.text, p.lo, p.hi, p.names); err != nil {
+			return cronSpec{}, err
+		}
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	c.domAny, c.dowAny = f[2] == "*", f[4] == "*"
+	return c, nil
+}
+
+// matches reports whether c fires in the minute of t. As in cron, when
+// both day fields are restricted a day matching either will do.
+func (c cronSpec) matches(t time.Time) bool {
+	has := func(bits uint64, v int) bool { return bits&(1<<v) != 0 }
+	if !has(c.minute, t.Minute()) || !has(c.hour, t.Hour()) || !has(c.month, int(t.Month())) {
+		return false
+	}
+	dom, dow := has(c.dom, t.Day()), has(c.dow, int(t.Weekday()))
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// cronRule applies the preset named preset whenever spec fires.
+type cronRule struct {
+	spec   cronSpec
+	preset string
+}
+
+// parseCronRule parses a rule such as "0 22 * * 1-5 apply Bedtime"; the
+// word "apply" may be left out.
+func parseCronRule(line string) (cronRule, error) {
+	f := strings.Fields(line)
+	if len(f) < 6 {
+		return cronRule{}, fmt.Errorf("%q: want a cron expression and a preset", line)
+	}
+	spec, err := parseCron(strings.Join(f[:5], " "))
+	if err != nil {
+		return cronRule{}, err
+	}
+	name := f[5:]
+	if len(name) > 1 && strings.EqualFold(name[0], "apply") {
+		name = name[1:]
+	}
+	return cronRule{spec, strings.Join(name, " ")}, nil
+}
+
+// syncCron runs the cron rules while there are any.
+func (u *uiState) syncCron() {
+	if u.cronStop != nil {
+		close(u.cronStop)
+		u.cronStop = nil
+	}
+	var rules []cronRule
+	for _, line := range u.cfg.CronRules {
+		r, err := parseCronRule(line)
+		if err != nil {
+			u.out.SetText("Cron rule skipped: " + err.Error())
+			continue
+		}
+		rules = append(rules, r)
+	}
+	if len(rules) == 0 {
+		return
+	}
+	stop := make(chan struct{})
+	u.cronStop = stop
+	go u.cronLoop(rules, stop)
+}
+
+// cronLoop wakes at the start of every minute and applies the presets
+// of the rules that fire then.
+func (u *uiState) cronLoop(rules []cronRule, stop chan struct{}) {
+	for {
+		now := time.Now()
+		next := now.Truncate(time.Minute).Add(time.Minute)
+		select {
+		case <-stop:
+			return
+		case <-time.After(time.Until(next)):
+		}
+		for _, r := range rules {
+			if !r.spec.matches(next) {
+				continue
+			}
+			fyne.Do(func() {
+				if u.cronStop == stop {
+					u.fireCron(r.preset)
+				}
+			})
+		}
+	}
+}
+
+func (u *uiState) fireCron(name string) {
+	for _, p := range u.presets() {
+		if strings.EqualFold(p.Name, name) {
+			u.applyPreset(p)
+			return
+		}
+	}
+	u.out.SetText("Cron rule: no preset called " + name)
+}
+
+// showCronRules edits the cron rules, one per line.
+func (u *uiState) showCronRules() {
+	rules := widget.NewMultiLineEntry()
+	rules.SetText(strings.Join(u.cfg.CronRules, "\n"))
+	rules.SetPlaceHolder("0 22 * * 1-5 apply Bedtime\n30 7 * * sat,sun apply Day")
+	rules.SetMinRowsVisible(6)
+	item := widget.NewFormItem("Rules", rules)
+	item.HintText = "minute hour day month weekday, then a preset"
+	dialog.ShowForm("Cron rules", "Save", "Cancel", []*widget.FormItem{item}, func(ok bool) {
+		if !ok {
+			return
+		}
+		var lines []string
+		for _, line := range strings.Split(rules.Text, "\n") {
+			if line = strings.TrimSpace(line); line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			if _, err := parseCronRule(line); err != nil {
+				u.out.SetText("Cron rules not saved: " + err.Error())
+				return
+			}
+			lines = append(lines, line)
+		}
+		u.cfg.CronRules = lines
+		u.saveConfig()
+		u.syncCron()
+		u.out.SetText(fmt.Sprintf("%d cron rules saved.", len(lines)))
+	}, u.win)
+}
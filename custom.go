@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const backendCustom = "custom"
+
+// customBackend runs a command the user wrote, for tools we don't know
+// about. The template goes to sh -c with {temp}, {brightness} and {gamma}
+// replaced by the settings; reset runs it with the defaults. It can only
+// show what the template has a placeholder for.
+type customBackend struct {
+	u *uiState
+}
+
+func (b *customBackend) Name() string { return backendCustom }
+
+// customCommand is Config.CustomCommand. Safe to call from any goroutine.
+func (u *uiState) customCommand() string {
+	if c := u.custom.Load(); c != nil {
+		return *c
+	}
+	return ""
+}
+
+func (b *customBackend) Available() bool { return b.u.customCommand() != "" }
+
+func (b *customBackend) Capabilities() Capabilities {
+	cmd := b.u.customCommand()
+	return Capabilities{
+		Temperature: strings.Contains(cmd, "{temp}"),
+		Brightness:  strings.Contains(cmd, "{brightness}"),
+		Gamma:       strings.Contains(cmd, "{gamma}"),
+		Remote:      true,
+	}
+}
+
+func (b *customBackend) Apply(ctx context.Context, s Settings) error {
+	cmd := b.u.customCommand()
+	if cmd == "" {
+		return errors.New("no custom command set; add one under Backend › Preferences")
+	}
+	cmd = strings.NewReplacer(
+		"{temp}", fmt.Sprint(s.TempK),
+		"{brightness}", fmt.Sprintf("%.2f", s.Brightness),
+		"{gamma}", fmt.Sprintf("%.2f", s.Gamma),
+	).Replace(cmd)
+	if out, err := b.u.exec(ctx, "sh", "-c", cmd); err != nil {
+		return commandError(out, err)
+	}
+	return nil
+}
+
+func (b *customBackend) Reset(ctx context.Context) error {
+	return b.Apply(ctx, defaultSettings)
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+// redshiftConfText is a redshift.conf that has redshift's continual mode
+// do what Auto would: the Auto day and night values, elevations or clock
+// times, and our location. It starts with the comment line header.
+func (u *uiState) redshiftConfText(header string) (string, error) {
+	day, night := u.cfg.AutoDay, u.cfg.AutoNight
+	dr, dg, db := day.channelGamma()
+	nr, ng, nb := night.channelGamma()
+	var b strings.Builder
+	fmt.Fprintf(&b, "; %s\n[redshift]\n", header)
+	fmt.Fprintf(&b, "temp-day=%d\ntemp-night=%d\n", day.TempK, night.TempK)
+	fmt.Fprintf(&b, "brightness-day=%.2f\nbrightness-night=%.2f\n", day.Brightness, night.Brightness)
+	fmt.Fprintf(&b, "gamma-day=%.2f:%.2f:%.2f\ngamma-night=%.2f:%.2f:%.2f\n", dr, dg, db, nr, ng, nb)
+	fmt.Fprintf(&b, "fade=1\n")
+	if m := u.pinnedMethod(); m != "" {
+		fmt.Fprintf(&b, "adjustment-method=%s\n", m)
+	}
+	if u.cfg.AutoClock {
+		// redshift takes clock times only, as start-end ranges.
+		window := func(at TimeSpec) (string, error) {
+			start, err := time.Parse("15:04", strings.TrimSpace(string(at)))
+			if err != nil {
+				return "", fmt.Errorf("redshift can't switch at %q, only at clock times", at)
+			}
+			end := start.Add(time.Duration(max(u.cfg.AutoFadeMinutes, 1)) * time.Minute)
+			return start.Format("15:04") + "-" + end.Format("15:04"), nil
+		}
+		dawn, err := window(u.cfg.AutoDayAt)
+		if err != nil {
+			return "", err
+		}
+		dusk, err := window(u.cfg.AutoNightAt)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "dawn-time=%s\ndusk-time=%s\n", dawn, dusk)
+	} else {
+		fmt.Fprintf(&b, "elevation-high=%g\nelevation-low=%g\n", u.cfg.AutoElevationHigh, u.cfg.AutoElevationLow)
+	}
+	switch l, ok := u.location(); {
+	case ok:
+		fmt.Fprintf(&b, "location-provider=manual\n[manual]\nlat=%g\nlon=%g\n", l.Lat, l.Lon)
+	case u.cfg.GeoClue:
+		fmt.Fprintf(&b, "location-provider=geoclue2\n")
+	case !u.cfg.AutoClock:
+		return "", errors.New("redshift needs a location; set one under Places › Location…")
+	}
+	return b.String(), nil
+}
+
+// redshiftConf writes redshiftConfText for our own daemon, next to our
+// config, and returns the file's path.
+func (u *uiState) redshiftConf() (string, error) {
+	cfgPath, err := configPath()
+	if err != nil {
+		return "", err
+	}
+	text, err := u.redshiftConfText("Written by Screen Dimmer; changes here are overwritten.")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(filepath.Dir(cfgPath), "redshift.conf")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	return path, writeFileAtomic(path, []byte(text), 0o644)
+}
+
+// setRedshiftDaemon hands day and night over to redshift running in
+// continual mode, or takes them back. It takes over from Auto and the
+// schedule, which would fight it for the gamma.
+func (u *uiState) setRedshiftDaemon(on bool) {
+	if on && u.remote.Load() != nil {
+		u.out.SetText("The redshift daemon only runs on this machine.")
+		return
+	}
+	u.cfg.RedshiftDaemon = on
+	u.saveConfig()
+	if !on {
+		go u.daemon.stop() // redshift puts the ramps back as it exits
+		u.out.SetText("redshift daemon stopped.")
+		return
+	}
+	u.setAuto(false)
+	u.setSchedule(false)
+	u.restartRedshiftDaemon()
+}
+
+// restartRedshiftDaemon writes the config afresh and starts redshift
+// again, e.g. after the sliders took over or the day and night values
+// changed. It ends any hold the sliders had on it.
+func (u *uiState) restartRedshiftDaemon() {
+	if u.daemonHold != nil {
+		u.daemonHold.Stop()
+		u.daemonHold = nil
+	}
+	u.daemonHeld.Store(false)
+	path, err := u.redshiftConf()
+	if err != nil {
+		u.out.SetText("redshift daemon: " + err.Error())
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		err := u.daemon.restart(ctx, "redshift", "-c", path)
+		fyne.Do(func() {
+			if err != nil {
+				u.out.SetText("redshift daemon: " + err.Error())
+			} else {
+				u.out.SetText("redshift is following day and night.")
+			}
+		})
+	}()
+}
+
+// daemonHoldFor is how long the sliders keep redshift off after they
+// were last moved.
+const daemonHoldFor = time.Hour
+
+// holdDaemon lets the sliders take over from the redshift daemon until
+// they have been left alone for daemonHoldFor. Call it on every change.
+func (u *uiState) holdDaemon() {
+	if !u.cfg.RedshiftDaemon {
+		return
+	}
+	if u.daemonHold != nil {
+		u.daemonHold.Stop()
+	}
+	var t *time.Timer
+	t = time.AfterFunc(daemonHoldFor, func() {
+		fyne.Do(func() {
+			if u.daemonHold == t {
+				u.daemonHold = nil
+				u.syncDaemonHold()
+			}
+		})
+	})
+	u.daemonHold = t
+	u.syncDaemonHold()
+}
+
+// syncDaemonHold keeps the daemon off while the sliders hold it or an
+// override is active, and starts it again once neither does.
+func (u *uiState) syncDaemonHold() {
+	held := u.daemonHold != nil || len(u.overrides) > 0
+	if u.daemonHeld.Swap(held) && !held && u.cfg.RedshiftDaemon {
+		u.restartRedshiftDaemon()
+	}
+}
+
+// yieldDaemon stops the daemon so an apply can show the sliders or an
+// override instead. It reports whether one was running. Safe to call
+// from any goroutine.
+func (u *uiState) yieldDaemon() bool {
+	if !u.daemon.running() {
+		return false
+	}
+	u.daemon.stop()
+	return true
+}
+
+// daemonMenu starts, stops and restarts the redshift daemon.
+func (u *uiState) daemonMenu() *fyne.Menu {
+	run := fyne.NewMenuItem("Let redshift follow day and night", func() { u.setRedshiftDaemon(!u.cfg.RedshiftDaemon) })
+	run.Checked = u.cfg.RedshiftDaemon
+	restart := fyne.NewMenuItem("Restart redshift", u.restartRedshiftDaemon)
+	restart.Disabled = !u.cfg.RedshiftDaemon
+	return fyne.NewMenu("redshift daemon", run, restart)
+}
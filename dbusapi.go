@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	busName      = "com.oriole.RedshiftControlPanel"
+	busPath      = dbus.ObjectPath("/com/oriole/RedshiftControlPanel")
+	busInterface = busName
+
+	inhibitedOverride = "Inhibited"
+)
+
+// dbusAPI is the object exported on the session bus for other programs.
+// Its methods are called on godbus goroutines.
+type dbusAPI struct {
+	u *uiState
+
+	mu      sync.Mutex
+	next    uint32
+	cookies map[uint32]string // cookie -> unique name of the caller
+}
+
+// startDBusAPI claims our well-known name on the session bus and serves
+// the API until the process exits.
+func (u *uiState) startDBusAPI() error {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return err
+	}
+	api := &dbusAPI{u: u, cookies: map[uint32]string{}}
+	if err := conn.Export(api, busPath, busInterface); err != nil {
+		conn.Close()
+		return err
+	}
+	reply, err := conn.RequestName(busName, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		conn.Close()
+		return errors.New("another instance is running")
+	}
+
+	// Drop the cookies of callers that exit without releasing them.
+	if err := conn.AddMatchSignal(dbus.WithMatchInterface("org.freedesktop.DBus"),
+		dbus.WithMatchMember("NameOwnerChanged")); err != nil {
+		return err
+	}
+	signals := make(chan *dbus.Signal, 16)
+	conn.Signal(signals)
+	go func() {
+		for sig := range signals {
+			if len(sig.Body) == 3 && sig.Body[2] == "" {
+				if name, ok := sig.Body[0].(string); ok {
+					api.releaseAll(name)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// Inhibit suspends all adjustments until UnInhibit is called with the
+// returned cookie or the caller leaves the bus. Screenshot tools, colour
+// pickers and calibration software use it to see the true colours.
+func (a *dbusAPI) Inhibit(sender dbus.Sender, app, reason string) (uint32, *dbus.Error) {
+	a.mu.Lock()
+	a.next++
+	cookie := a.next
+	a.cookies[cookie] = string(sender)
+	a.mu.Unlock()
+	fyne.Do(func() {
+		a.u.pushOverride(inhibitedOverride, defaultSettings)
+		a.u.out.SetText("Paused by " + app + ": " + reason)
+	})
+	return cookie, nil
+}
+
+// UnInhibit releases a cookie from Inhibit.
+func (a *dbusAPI) UnInhibit(sender dbus.Sender, cookie uint32) *dbus.Error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if owner, ok := a.cookies[cookie]; !ok || owner != string(sender) {
+		return dbus.MakeFailedError(errors.New("unknown cookie"))
+	}
+	delete(a.cookies, cookie)
+	a.syncLocked()
+	return nil
+}
+
+func (a *dbusAPI) releaseAll(name string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	released := false
+	for c, owner := range a.cookies {
+		if owner == name {
+			delete(a.cookies, c)
+			released = true
+		}
+	}
+	if released {
+		a.syncLocked()
+	}
+}
+
+// syncLocked ends the override once no cookie is left. a.mu must be held.
+func (a *dbusAPI) syncLocked() {
+	if len(a.cookies) > 0 {
+		return
+	}
+	fyne.Do(func() { a.u.popOverride(inhibitedOverride) })
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+)
+
+// Hardware dimming modes for Config.DDC and Config.Backlight.
+const (
+	hardwareOff     = ""
+	hardwareInstead = "instead" // the hardware does all the dimming, gamma none
+	hardwareAlso    = "also"    // the hardware dims down to its floor, gamma the rest
+)
+
+// ddcFloor is the lowest monitor brightness "also" mode uses; below it
+// many panels get noisy or switch the backlight off.
+const ddcFloor = 0.20
+
+var ddcBusRe = regexp.MustCompile(`I2C bus:\s*/dev/i2c-(\d+)`)
+
+// ddcState is the monitors found by ddcutil and the levels last sent, so
+// a drag that doesn't change a level costs nothing: every write takes
+// tens of milliseconds on the I²C bus.
+type ddcState struct {
+	mu    sync.Mutex
+	buses []int       // nil until detected
+	last  map[int]int // bus -> percent last set
+}
+
+// detectDDC lists the I²C buses of monitors that answer DDC/CI.
+func (u *uiState) detectDDC(ctx context.Context) ([]int, error) {
+	out, err := u.exec(ctx, "ddcutil", "detect", "--terse")
+	if err != nil {
+		return nil, commandError(out, err)
+	}
+	var buses []int
+	for _, m := range ddcBusRe.FindAllStringSubmatch(string(out), -1) {
+		n, _ := strconv.Atoi(m[1])
+		buses = append(buses, n)
+	}
+	return buses, nil
+}
+
+// hardwareSplit divides brightness b between the hardware and the gamma
+// ramp for mode; in hardwareAlso mode the hardware stops at floor.
+func hardwareSplit(mode string, b, floor float64) (hardware, gamma float64) {
+	switch mode {
+	case hardwareInstead:
+		return b, 1
+	case hardwareAlso:
+		hardware = math.Max(b, floor)
+		return hardware, b / hardware
+	}
+	return 1, b
+}
+
+// applyHardware hands the laptop backlight and DDC/CI monitors their
+// shares of s.Brightness, each by its own mode, and returns s with what is
+// left for the gamma: the smaller share, so no screen ends up brighter
+// than asked. It also dims the keyboard at night. The returned text
+// reports any hardware errors.
+func (u *uiState) applyHardware(ctx context.Context, s Settings) (Settings, string) {
+	d, ddcMsg := u.applyDDC(ctx, s)
+	l, blMsg := u.applyBacklight(s)
+	s.Brightness = math.Min(d.Brightness, l.Brightness)
+	return s, ddcMsg + blMsg + u.applyKeyboard(s)
+}
+
+// applyDDC sends the monitor's share of s.Brightness over DDC/CI and
+// returns s with the brightness left for the backend. Errors are
+// reported in the returned text, since the gamma still applies.
+func (u *uiState) applyDDC(ctx context.Context, s Settings) (Settings, string) {
+	mode := hardwareOff
+	if m := u.ddcMode.Load(); m != nil {
+		mode = *m
+	}
+	if mode == hardwareOff {
+		return s, ""
+	}
+	monitor, gamma := hardwareSplit(mode, s.Brightness, ddcFloor)
+	s.Brightness = gamma
+	pct := int(math.Round(monitor * 100))
+
+	d := &u.ddc
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.buses == nil {
+		buses, err := u.detectDDC(ctx)
+		if err != nil {
+			return s, " DDC/CI: " + err.Error()
+		}
+		d.buses, d.last = buses, map[int]int{}
+	}
+	var failed []string
+	for _, bus := range d.buses {
+		if d.last[bus] == pct {
+			continue
+		}
+		out, err := u.exec(ctx, "ddcutil", "--bus", strconv.Itoa(bus), "setvcp", "10", strconv.Itoa(pct))
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("bus %d: %v", bus, commandError(out, err)))
+			continue
+		}
+		d.last[bus] = pct
+	}
+	if len(d.buses) == 0 {
+		return s, " DDC/CI: no monitor answered."
+	}
+	if len(failed) > 0 {
+		return s, " DDC/CI " + strings.Join(failed, "; ") + "."
+	}
+	return s, ""
+}
+
+// setDDC picks the DDC/CI mode. Monitors are detected again on the next
+// apply, so plugging one in and re-picking the mode finds it. Leaving
+// DDC/CI puts the monitors back to full brightness.
+func (u *uiState) setDDC(mode string) {
+	old := u.cfg.DDC
+	u.cfg.DDC = mode
+	u.saveConfig()
+	u.ddcMode.Store(&mode)
+	u.syncControls()
+	go func() {
+		d := &u.ddc
+		d.mu.Lock()
+		if old != hardwareOff && mode == hardwareOff {
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			for _, bus := range d.buses {
+				u.exec(ctx, "ddcutil", "--bus", strconv.Itoa(bus), "setvcp", "10", "100")
+			}
+			cancel()
+		}
+		d.buses, d.last = nil, nil
+		d.mu.Unlock()
+		fyne.Do(func() { u.scheduleApply(u.target()) })
+	}()
+}
+
+// hardwareMenu offers the hardware dimming modes, current checked.
+func hardwareMenu(current string, set func(mode string)) *fyne.Menu {
+	var items []*fyne.MenuItem
+	for _, m := range []struct{ mode, label string }{
+		{hardwareOff, "Off (gamma only)"},
+		{hardwareInstead, "Instead of gamma dimming"},
+		{hardwareAlso, "Hardware first, then gamma"},
+	} {
+		item := fyne.NewMenuItem(m.label, func() { set(m.mode) })
+		item.Checked = current == m.mode
+		items = append(items, item)
+	}
+	return fyne.NewMenu("", items...)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/randr"
+)
+
+const (
+	ditherBits     = 8                     // output depth we assume the link truncates to
+	ditherPhases   = 4                     // frames per cycle; 2 bits of extra precision
+	ditherInterval = 16 * time.Millisecond // about one frame at 60 Hz
+	ditherBelow    = 0.50                  // brightness under which banding shows
+	ditherGamma    = 0.30                  // or gamma this far from 1
+)
+
+// needsDither reports whether s is extreme enough for banding to show.
+func needsDither(s Settings) bool {
+	return s.Brightness < ditherBelow || math.Abs(s.Gamma-1) > ditherGamma
+}
+
+// ditherFrames splits r into phases ramps quantized to ditherBits whose
+// average over a cycle is r, like the FRC of a 6-bit panel. Thresholds are
+// staggered per entry so neighbouring levels don't flip on the same frame.
+func ditherFrames(r Ramp) []Ramp {
+	const top = 1<<ditherBits - 1
+	frames := make([]Ramp, ditherPhases)
+	for p := range frames {
+		frames[p] = Ramp{R: make([]uint16, r.Size()), G: make([]uint16, r.Size()), B: make([]uint16, r.Size())}
+	}
+	quant := func(in []uint16, out func(p int) []uint16) {
+		for i, v := range in {
+			x := float64(v) / math.MaxUint16 * top
+			lo := math.Floor(x)
+			frac := x - lo
+			for p := 0; p < ditherPhases; p++ {
+				level := lo
+				if frac > (float64((p+i)%ditherPhases)+0.5)/ditherPhases {
+					level++
+				}
+				out(p)[i] = uint16(math.Round(math.Min(level, top) / top * math.MaxUint16))
+			}
+		}
+	}
+	quant(r.R, func(p int) []uint16 { return frames[p].R })
+	quant(r.G, func(p int) []uint16 { return frames[p].G })
+	quant(r.B, func(p int) []uint16 { return frames[p].B })
+	return frames
+}
+
+// startDither cycles every seat CRTC of screens through the dither frames of its
+// ramp until stop is closed. The returned channel closes once the last
+// frame has gone out, so the caller can write new ramps without a stale
+// frame landing on top.
+func startDither(foreign map[string]bool, screens []int, rampFor func(crtc, size int) Ramp, stop chan struct{}) (done chan struct{}) {
+	done = make(chan struct{})
+	X, err := xgb.NewConn()
+	if err != nil {
+		close(done)
+		return done
+	}
+	crtcs, idx, err := screensCRTCs(X, screens, foreign)
+	if err != nil {
+		X.Close()
+		close(done)
+		return done
+	}
+	type target struct {
+		crtc   randr.Crtc
+		frames []Ramp
+	}
+	var targets []target
+	for i, crtc := range crtcs {
+		gs, err := randr.GetCrtcGammaSize(X, crtc).Reply()
+		if err != nil || gs.Size < 2 {
+			continue
+		}
+		r := rampFor(idx[i], int(gs.Size))
+		targets = append(targets, target{crtc, ditherFrames(r)})
+	}
+
+	go func() {
+		defer close(done)
+		defer X.Close()
+		tick := time.NewTicker(ditherInterval)
+		defer tick.Stop()
+		for phase := 0; ; phase = (phase + 1) % ditherPhases {
+			select {
+			case <-stop:
+				X.Sync()
+				return
+			case <-tick.C:
+			}
+			for _, t := range targets {
+				f := t.frames[phase]
+				randr.SetCrtcGamma(X, t.crtc, uint16(f.Size()), f.R, f.G, f.B)
+			}
+		}
+	}()
+	return done
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const backendDRM = "drm"
+
+// drmBackend runs redshift -m drm on every graphics card, for consoles
+// and kiosks with neither X nor Wayland. KMS only lets the DRM master set
+// gamma, so it fails while a display server owns the card.
+type drmBackend struct {
+	u *uiState
+}
+
+func (b *drmBackend) Name() string { return backendDRM }
+
+func (b *drmBackend) Capabilities() Capabilities {
+	return Capabilities{Temperature: true, Brightness: true, Gamma: true, ChannelGamma: true}
+}
+
+func (b *drmBackend) Available() bool {
+	return hasCommand("redshift") && len(drmCards()) > 0
+}
+
+// consoleSession reports whether we run without a display server.
+func consoleSession() bool {
+	return os.Getenv("DISPLAY") == "" && !waylandSession()
+}
+
+// drmCards returns the number of every /dev/dri/cardN, which is what
+// redshift's drm:card= expects.
+func drmCards() []string {
+	paths, _ := filepath.Glob("/dev/dri/card[0-9]*")
+	cards := make([]string, len(paths))
+	for i, p := range paths {
+		cards[i] = strings.TrimPrefix(filepath.Base(p), "card")
+	}
+	return cards
+}
+
+func (b *drmBackend) Apply(ctx context.Context, s Settings) error {
+	gr, gg, gb := s.channelGamma()
+	return b.each(ctx,
+		"-P",
+		"-O", fmt.Sprint(s.TempK),
+		"-g", fmt.Sprintf("%.2f:%.2f:%.2f", gr, gg, gb),
+		"-b", fmt.Sprintf("%.2f", s.Brightness))
+}
+
+func (b *drmBackend) Reset(ctx context.Context) error {
+	return b.each(ctx, "-x")
+}
+
+// each runs redshift -m drm:card=N args... for every card.
+func (b *drmBackend) each(ctx context.Context, args ...string) error {
+	if b.u.remote.Load() != nil {
+		return errors.New("the drm backend only reaches this machine's cards")
+	}
+	cards := drmCards()
+	if len(cards) == 0 {
+		return errors.New("no DRM devices in /dev/dri")
+	}
+	for _, c := range cards {
+		if out, err := b.u.exec(ctx, "redshift", append([]string{"-m", "drm:card=" + c}, args...)...); err != nil {
+			return commandError(out, err)
+		}
+	}
+	return nil
+}
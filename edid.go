@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// edidInfo is what identifies a monitor in its EDID base block.
+type edidInfo struct {
+	maker   string // three-letter PNP ID, e.g. "DEL"
+	product uint16
+	serial  string // the serial string descriptor, else the numeric serial
+	name    string // the monitor name descriptor, "" if absent
+}
+
+var edidHeader = "\x00\xff\xff\xff\xff\xff\xff\x00"
+
+// parseEDID reads the identity out of an EDID, reporting false when the
+// data isn't one.
+func parseEDID(edid string) (edidInfo, bool) {
+	if len(edid) < 128 || edid[:8] != edidHeader {
+		return edidInfo{}, false
+	}
+	b := []byte(edid)
+	m := binary.BigEndian.Uint16(b[8:])
+	info := edidInfo{
+		maker:   string([]byte{byte(m>>10&31) + '@', byte(m>>5&31) + '@', byte(m&31) + '@'}),
+		product: binary.LittleEndian.Uint16(b[10:]),
+	}
+	if n := binary.LittleEndian.Uint32(b[12:]); n != 0 {
+		info.serial = fmt.Sprint(n)
+	}
+	for off := 54; off+18 <= 126; off += 18 {
+		d := b[off : off+18]
+		if d[0] != 0 || d[1] != 0 {
+			continue // a timing, not a display descriptor
+		}
+		text := strings.TrimSpace(strings.SplitN(string(d[5:]), "\n", 2)[0])
+		switch d[3] {
+		case 0xff:
+			info.serial = text
+		case 0xfc:
+			info.name = text
+		}
+	}
+	return info, true
+}
+
+// key identifies the physical monitor across connectors and reboots.
+func (e edidInfo) key() string {
+	return fmt.Sprintf("%s-%04x-%s", e.maker, e.product, e.serial)
+}
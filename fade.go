@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+// fadeStep is how often a fade moves the sliders at most. It is longer
+// than the apply debounce so every step reaches the screen. Long fades
+// take fadeSteps steps instead.
+const (
+	fadeStep  = 300 * time.Millisecond
+	fadeSteps = 200
+)
+
+// fadeChoices are the Preset fade menu's durations, in seconds.
+var fadeChoices = []int{0, 2, 5, 10, 30}
+
+// fadeTo moves the sliders to s over Config.FadeSeconds, easing in and
+// out, or at once when fading is off. A drag or another fade ends it.
+func (u *uiState) fadeTo(s Settings) {
+	u.fadeOver(s, time.Duration(u.cfg.FadeSeconds)*time.Second)
+}
+
+// fadeOver is fadeTo taking d.
+func (u *uiState) fadeOver(s Settings, d time.Duration) {
+	u.stopFade()
+	if d <= 0 {
+		u.setSliders(s)
+		u.scheduleApply(u.target())
+		u.syncTray()
+		return
+	}
+	stop := make(chan struct{})
+	u.fadeStop = stop
+	go u.fadeLoop(u.current(), s, d, stop)
+}
+
+func (u *uiState) stopFade() {
+	if u.fadeStop != nil {
+		close(u.fadeStop)
+		u.fadeStop = nil
+	}
+}
+
+func (u *uiState) fadeLoop(from, to Settings, d time.Duration, stop chan struct{}) {
+	tick := time.NewTicker(max(fadeStep, d/fadeSteps))
+	defer tick.Stop()
+	start := time.Now()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-tick.C:
+		}
+		t := min(float64(time.Since(start))/float64(d), 1)
+		s := lerp(from, to, t*t*(3-2*t)) // smoothstep
+		fyne.Do(func() {
+			if u.fadeStop != stop {
+				return
+			}
+			u.setSliders(s)
+			u.scheduleApply(u.target())
+			if t == 1 {
+				u.fadeStop = nil
+				u.syncTray()
+			}
+		})
+		if t == 1 {
+			return
+		}
+	}
+}
+
+func (u *uiState) setFade(seconds int) {
+	u.cfg.FadeSeconds = seconds
+	u.saveConfig()
+}
+
+// fadeMenu picks how long switching presets takes.
+func (u *uiState) fadeMenu() *fyne.Menu {
+	var items []*fyne.MenuItem
+	for _, n := range fadeChoices {
+		label := "Off"
+		if n > 0 {
+			label = fmt.Sprintf("%d seconds", n)
+		}
+		item := fyne.NewMenuItem(label, func() { u.setFade(n) })
+		item.Checked = u.cfg.FadeSeconds == n
+		items = append(items, item)
+	}
+	return fyne.NewMenu("", items...)
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"sync"
+)
+
+// inFlatpak reports whether we run inside a Flatpak sandbox, where the
+// colour tools are on the host and have to be reached through
+// flatpak-spawn.
+var inFlatpak = sync.OnceValue(func() bool {
+	_, err := os.Stat("/.flatpak-info")
+	return err == nil
+})
+
+// hostCommand returns the command that runs name with args on the host.
+func hostCommand(name string, args ...string) (string, []string) {
+	if !inFlatpak() {
+		return name, args
+	}
+	return "flatpak-spawn", append([]string{"--host", name}, args...)
+}
+
+// hasCommand reports whether name is installed where hostCommand runs it.
+func hasCommand(name string) bool {
+	if !inFlatpak() {
+		_, err := exec.LookPath(name)
+		return err == nil
+	}
+	return exec.Command("flatpak-spawn", "--host", "sh", "-c", `command -v "$1" >/dev/null`, "sh", name).Run() == nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"time"
+
+	"fyne.io/fyne/v2"
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+const focusPollInterval = time.Second
+
+// setFocusEmphasis starts or stops emphasizing the monitor that shows the
+// focused window: the others are kept a little dimmer and warmer.
+func (u *uiState) setFocusEmphasis(on bool) {
+	if on == (u.focusStop != nil) {
+		return
+	}
+	u.cfg.FocusEmphasis = on
+	u.saveConfig()
+	if !on {
+		close(u.focusStop)
+		u.focusStop = nil
+		u.focused.Store(0)
+		u.scheduleApply(u.target())
+		return
+	}
+	stop := make(chan struct{})
+	u.focusStop = stop
+	go u.focusLoop(stop)
+}
+
+// focusLoop follows the active window across monitors and re-applies
+// whenever it lands on a different one.
+func (u *uiState) focusLoop(stop chan struct{}) {
+	tick := time.NewTicker(focusPollInterval)
+	defer tick.Stop()
+	for {
+		if crtc := activeCRTC(); crtc >= 0 && int32(crtc+1) != u.focused.Load() {
+			fyne.Do(func() {
+				if u.focusStop == stop {
+					u.focused.Store(int32(crtc + 1))
+					u.scheduleApply(u.target())
+				}
+			})
+		}
+		select {
+		case <-stop:
+			return
+		case <-tick.C:
+		}
+	}
+}
+
+// focusedCRTC returns the CRTC to emphasize, or -1 when emphasis is off or
+// the focused monitor isn't known yet. Safe to call from any goroutine.
+func (u *uiState) focusedCRTC() int {
+	return int(u.focused.Load()) - 1
+}
+
+// focusShift is what the monitors without focus lose.
+type focusShift struct {
+	warmK int
+	dim   float64
+}
+
+// unfocused is s as shown on the monitors without focus. Safe to call
+// from any goroutine.
+func (u *uiState) unfocused(s Settings) Settings {
+	f := u.unfocus.Load()
+	return u.limits.Load().clamp(s.shifted(-f.warmK, -f.dim))
+}
+
+// activeCRTC returns the index of the CRTC under the centre of the active
+// window, or -1.
+func activeCRTC() int {
+	X, err := xgb.NewConn()
+	if err != nil {
+		return -1
+	}
+	defer X.Close()
+
+	win, err := activeWindow(X)
+	if err != nil {
+		return -1
+	}
+	geo, err := xproto.GetGeometry(X, xproto.Drawable(win)).Reply()
+	if err != nil {
+		return -1
+	}
+	root := xproto.Setup(X).DefaultScreen(X).Root
+	pos, err := xproto.TranslateCoordinates(X, win, root, int16(geo.Width/2), int16(geo.Height/2)).Reply()
+	if err != nil {
+		return -1
+	}
+	return crtcAt(X, int(pos.DstX), int(pos.DstY))
+}
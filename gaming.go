@@ -0,0 +1,36 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"fyne.io/fyne/v2/widget"
+)
+
+const gamingOverride = "Gaming"
+
+// toggleGaming flips Gaming mode: neutral gamma and a brightness boost on
+// top of the current sliders, undone when toggled again or when the
+// configured timer runs out.
+func (u *uiState) toggleGaming() {
+	if u.hasOverride(gamingOverride) {
+		u.popOverride(gamingOverride)
+		return
+	}
+
+	s := u.current()
+	s.Gamma = 1.00
+	s.Brightness = math.Min(s.Brightness+u.cfg.GamingBoost, 1.00)
+	u.pushTimedOverride(gamingOverride, s, time.Duration(u.cfg.GamingMinutes)*time.Minute)
+}
+
+// syncGaming keeps the header button in step with the override, which can
+// also end because the user moved a slider or the timer ran out.
+func (u *uiState) syncGaming() {
+	if u.hasOverride(gamingOverride) {
+		u.gamingBtn.Importance = widget.HighImportance
+	} else {
+		u.gamingBtn.Importance = widget.MediumImportance
+	}
+	u.gamingBtn.Refresh()
+}
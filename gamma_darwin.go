@@ -0,0 +1,66 @@
+//go:build darwin && cgo
+
+package main
+
+/*
+#cgo LDFLAGS: -framework CoreGraphics
+#include <CoreGraphics/CoreGraphics.h>
+*/
+import "C"
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+const backendMac = "coregraphics"
+
+// platformBackend is the backend an unset Config.Backend means on this
+// platform.
+const platformBackend = backendMac
+
+// platformBackends are the backends only this platform has.
+func platformBackends(u *uiState) []Backend {
+	return []Backend{&macBackend{u}}
+}
+
+// macBackend sets every active display's transfer formula through
+// CoreGraphics: each channel is scaled to the white point and brightness
+// and given its gamma. macOS restores the ColorSync profile when we exit.
+type macBackend struct {
+	u *uiState
+}
+
+func (b *macBackend) Name() string { return backendMac }
+
+func (b *macBackend) Capabilities() Capabilities {
+	return Capabilities{Temperature: true, Brightness: true, Gamma: true, ChannelGamma: true, WhitePoint: true}
+}
+
+func (b *macBackend) Apply(_ context.Context, s Settings) error {
+	const maxDisplays = 16
+	var ids [maxDisplays]C.CGDirectDisplayID
+	var n C.uint32_t
+	if err := C.CGGetActiveDisplayList(maxDisplays, &ids[0], &n); err != C.kCGErrorSuccess {
+		return fmt.Errorf("listing displays failed (CGError %d)", int(err))
+	}
+	wr, wg, wb := s.white()
+	gr, gg, gb := s.channelGamma() // blue reduction is in the blue gamma
+	// The formula is min + (max-min) * x^gamma, so our gamma is inverted.
+	for _, id := range ids[:n] {
+		err := C.CGSetDisplayTransferByFormula(id,
+			0, C.CGGammaValue(math.Min(wr*s.Brightness, 1)), C.CGGammaValue(1/gr),
+			0, C.CGGammaValue(math.Min(wg*s.Brightness, 1)), C.CGGammaValue(1/gg),
+			0, C.CGGammaValue(math.Min(wb*s.Brightness, 1)), C.CGGammaValue(1/gb))
+		if err != C.kCGErrorSuccess {
+			return fmt.Errorf("display %d refused the transfer formula (CGError %d)", int(id), int(err))
+		}
+	}
+	return nil
+}
+
+func (b *macBackend) Reset(context.Context) error {
+	C.CGDisplayRestoreColorSyncSettings()
+	return nil
+}
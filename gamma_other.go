@@ -0,0 +1,10 @@
+//go:build !windows && !(darwin && cgo)
+
+package main
+
+// platformBackend is the backend an unset Config.Backend means on this
+// platform; "" leaves it to the session.
+const platformBackend = ""
+
+// platformBackends are the backends only this platform has.
+func platformBackends(*uiState) []Backend { return nil }
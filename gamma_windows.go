@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"unsafe"
+)
+
+const backendWindows = "windows-gdi"
+
+// platformBackend is the backend an unset Config.Backend means on this
+// platform.
+const platformBackend = backendWindows
+
+var (
+	user32                 = syscall.NewLazyDLL("user32.dll")
+	gdi32                  = syscall.NewLazyDLL("gdi32.dll")
+	procGetDC              = user32.NewProc("GetDC")
+	procReleaseDC          = user32.NewProc("ReleaseDC")
+	procSetDeviceGammaRamp = gdi32.NewProc("SetDeviceGammaRamp")
+)
+
+// platformBackends are the backends only this platform has.
+func platformBackends(u *uiState) []Backend {
+	return []Backend{&windowsBackend{u}}
+}
+
+// windowsBackend computes the ramps like the RandR one and hands them to
+// GDI's SetDeviceGammaRamp for the whole desktop. Windows only takes
+// 256-entry tables and refuses ramps too far from identity, which in
+// practice limits how warm and how dim it goes.
+type windowsBackend struct {
+	u *uiState
+}
+
+func (b *windowsBackend) Name() string { return backendWindows }
+
+func (b *windowsBackend) Capabilities() Capabilities {
+	return Capabilities{Temperature: true, Brightness: true, Gamma: true, ChannelGamma: true, Curves: true, WhitePoint: true}
+}
+
+func (b *windowsBackend) Apply(_ context.Context, s Settings) error {
+	r := computeRampRef(s, b.u.base.Load(), refCurve(b.u.reference.Load()), 256)
+	var ramp [3][256]uint16
+	copy(ramp[0][:], r.R)
+	copy(ramp[1][:], r.G)
+	copy(ramp[2][:], r.B)
+
+	hdc, _, _ := procGetDC.Call(0)
+	if hdc == 0 {
+		return errors.New("no device context for the desktop")
+	}
+	defer procReleaseDC.Call(0, hdc)
+	if ok, _, _ := procSetDeviceGammaRamp.Call(hdc, uintptr(unsafe.Pointer(&ramp))); ok == 0 {
+		return errors.New("SetDeviceGammaRamp refused the ramp; Windows limits how far it may be from neutral")
+	}
+	return nil
+}
+
+func (b *windowsBackend) Reset(ctx context.Context) error {
+	return b.Apply(ctx, defaultSettings)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+const backendGammastep = "gammastep"
+
+// startupGrace is how long a spawned colour daemon gets to fail before we
+// take it as running.
+const startupGrace = 300 * time.Millisecond
+
+// process is a long-running helper that holds the gamma: Wayland
+// compositors restore the ramps as soon as the client that set them
+// disconnects, so one-shot tools have to stay alive.
+type process struct {
+	mu   sync.Mutex
+	cmd  *exec.Cmd
+	done chan struct{} // closed when cmd has exited
+}
+
+// restart replaces the running helper, if any, with name args. It returns
+// the helper's output as the error if it exits within startupGrace.
+func (p *process) restart(ctx context.Context, name string, args ...string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stopLocked()
+
+	var out bytes.Buffer
+	name, args = hostCommand(name, args...)
+	cmd := exec.Command(name, args...) // not ctx: it must outlive the apply
+	cmd.Stdout, cmd.Stderr = &out, &out
+	dieWithParent(cmd) // but not us
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	var err error
+	go func() {
+		err = cmd.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		if err == nil {
+			return nil // a backend that sets the gamma and exits
+		}
+		return commandError(out.Bytes(), err)
+	case <-time.After(startupGrace):
+	case <-ctx.Done():
+	}
+	p.cmd, p.done = cmd, done
+	return nil
+}
+
+// running reports whether a helper we started is still up.
+func (p *process) running() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cmd == nil {
+		return false
+	}
+	select {
+	case <-p.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// stop ends the running helper and waits for it to exit.
+func (p *process) stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stopLocked()
+}
+
+func (p *process) stopLocked() {
+	if p.cmd == nil {
+		return
+	}
+	p.cmd.Process.Signal(os.Interrupt) // lets it restore the ramps itself
+	select {
+	case <-p.done:
+	case <-time.After(time.Second):
+		p.cmd.Process.Kill()
+		<-p.done
+	}
+	p.cmd, p.done = nil, nil
+}
+
+// gammastepBackend drives gammastep, redshift's Wayland-capable fork, in
+// one-shot mode. On Wayland it keeps running to hold the gamma, so each
+// apply replaces the previous instance.
+type gammastepBackend struct {
+	u    *uiState
+	proc process
+}
+
+func (b *gammastepBackend) Name() string { return backendGammastep }
+
+func (b *gammastepBackend) Capabilities() Capabilities {
+	return Capabilities{Temperature: true, Brightness: true, Gamma: true, ChannelGamma: true}
+}
+
+func (b *gammastepBackend) Available() bool {
+	return hasCommand("gammastep")
+}
+
+func (b *gammastepBackend) Apply(ctx context.Context, s Settings) error {
+	method := b.u.pinnedMethod()
+	switch {
+	case method != "":
+	case waylandSession():
+		method = "wayland"
+	default:
+		method = "randr" // exits once the ramps are set, like redshift
+	}
+	gr, gg, gb := s.channelGamma()
+	return b.proc.restart(ctx, "gammastep", "-m", method, "-P",
+		"-O", fmt.Sprintf("%d", s.TempK),
+		"-g", fmt.Sprintf("%.2f:%.2f:%.2f", gr, gg, gb),
+		"-b", fmt.Sprintf("%.2f", s.Brightness))
+}
+
+// Reset stops gammastep; the compositor puts the original ramps back.
+func (b *gammastepBackend) Reset(context.Context) error {
+	b.proc.stop()
+	return nil
+}
+
+// waylandSession reports whether we are running under a Wayland
+// compositor, where redshift's randr method can't reach the outputs.
+func waylandSession() bool {
+	return os.Getenv("WAYLAND_DISPLAY") != ""
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	geoclueName    = "org.freedesktop.GeoClue2"
+	geoclueTimeout = 30 * time.Second
+	geoclueCity    = 4 // GClueAccuracyLevel: sunrise and sunset need no better
+)
+
+// geoclueLocation asks GeoClue2 once where we are, at city accuracy.
+// The first fix can take a while, as GeoClue may have to scan Wi-Fi.
+func geoclueLocation(ctx context.Context) (Location, error) {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return Location{}, err
+	}
+	var path dbus.ObjectPath
+	err = conn.Object(geoclueName, "/org/freedesktop/GeoClue2/Manager").
+		CallWithContext(ctx, geoclueName+".Manager.GetClient", 0).Store(&path)
+	if err != nil {
+		return Location{}, err
+	}
+	client := conn.Object(geoclueName, path)
+	if err := errors.Join(
+		client.SetProperty(geoclueName+".Client.DesktopId", dbus.MakeVariant("com.oriole.redshiftcontrolpanel")),
+		client.SetProperty(geoclueName+".Client.RequestedAccuracyLevel", dbus.MakeVariant(uint32(geoclueCity))),
+	); err != nil {
+		return Location{}, err
+	}
+
+	match := []dbus.MatchOption{
+		dbus.WithMatchObjectPath(path),
+		dbus.WithMatchInterface(geoclueName + ".Client"),
+		dbus.WithMatchMember("LocationUpdated"),
+	}
+	if err := conn.AddMatchSignalContext(ctx, match...); err != nil {
+		return Location{}, err
+	}
+	defer conn.RemoveMatchSignal(match...)
+	signals := make(chan *dbus.Signal, 4)
+	conn.Signal(signals)
+	defer conn.RemoveSignal(signals)
+
+	if err := client.CallWithContext(ctx, geoclueName+".Client.Start", 0).Err; err != nil {
+		return Location{}, err
+	}
+	defer client.Call(geoclueName+".Client.Stop", 0)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return Location{}, errors.New("no fix yet")
+		case sig := <-signals:
+			if sig.Path != path || len(sig.Body) < 2 {
+				continue
+			}
+			at, _ := sig.Body[1].(dbus.ObjectPath)
+			loc := conn.Object(geoclueName, at)
+			lat, err := loc.GetProperty(geoclueName + ".Location.Latitude")
+			if err != nil {
+				return Location{}, err
+			}
+			lon, err := loc.GetProperty(geoclueName + ".Location.Longitude")
+			if err != nil {
+				return Location{}, err
+			}
+			l := Location{}
+			l.Lat, _ = lat.Value().(float64)
+			l.Lon, _ = lon.Value().(float64)
+			return l, nil
+		}
+	}
+}
+
+// setGeoClue chooses whether Auto may ask GeoClue where we are.
+func (u *uiState) setGeoClue(on bool) {
+	u.cfg.GeoClue = on
+	u.saveConfig()
+	if on && u.autoStop != nil {
+		u.locate()
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	backendGNOME     = "gnome-night-light"
+	nightLightSchema = "org.gnome.settings-daemon.plugins.color"
+)
+
+// gnomeSession reports whether we run under GNOME Shell, which applies its
+// own Night Light and resets other clients' ramps.
+func gnomeSession() bool {
+	return strings.Contains(os.Getenv("XDG_CURRENT_DESKTOP"), "GNOME")
+}
+
+// nightLightKeys are the Night Light keys Apply changes, in the order
+// they are written.
+var nightLightKeys = []string{
+	"night-light-schedule-automatic",
+	"night-light-schedule-from",
+	"night-light-schedule-to",
+	"night-light-temperature",
+	"night-light-enabled",
+}
+
+// gnomeBackend drives GNOME's Night Light through its gsettings keys: it
+// is switched on with a manual schedule covering the whole day and set to
+// the target temperature. Night Light only does temperature. The user's
+// own schedule is read before the first apply and put back by Reset.
+type gnomeBackend struct {
+	u     *uiState
+	mu    sync.Mutex
+	saved []string // key, value pairs from before our first apply; nil when not taken over
+}
+
+func (b *gnomeBackend) Name() string { return backendGNOME }
+
+func (b *gnomeBackend) Capabilities() Capabilities { return Capabilities{Temperature: true} }
+
+// Limits: Night Light only warms, and not past its key's minimum.
+func (b *gnomeBackend) Limits() Ranges {
+	l := redshiftLimits
+	l.TempMin, l.TempMax = 1000, 6500
+	return l
+}
+
+func (b *gnomeBackend) Available() bool {
+	return hasCommand("gsettings") && gnomeSession()
+}
+
+func (b *gnomeBackend) Apply(ctx context.Context, s Settings) error {
+	if err := b.save(ctx); err != nil {
+		return err
+	}
+	return b.set(ctx,
+		"night-light-schedule-automatic", "false",
+		"night-light-schedule-from", "0.0",
+		"night-light-schedule-to", "23.99", // a full day; equal ends mean none
+		"night-light-temperature", fmt.Sprint(s.TempK),
+		"night-light-enabled", "true")
+}
+
+// Reset puts back the Night Light settings the user had before our first
+// apply. Without those it turns Night Light off.
+func (b *gnomeBackend) Reset(ctx context.Context) error {
+	b.mu.Lock()
+	saved := b.saved
+	b.saved = nil
+	b.mu.Unlock()
+	if saved == nil {
+		return b.set(ctx, "night-light-enabled", "false")
+	}
+	return b.set(ctx, saved...)
+}
+
+// save reads the user's Night Light settings, once per takeover. Values
+// come back in GVariant text, which set accepts as is.
+func (b *gnomeBackend) save(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.saved != nil {
+		return nil
+	}
+	var saved []string
+	for _, k := range nightLightKeys {
+		out, err := b.u.exec(ctx, "gsettings", "get", nightLightSchema, k)
+		if err != nil {
+			return commandError(out, err)
+		}
+		saved = append(saved, k, strings.TrimSpace(string(out)))
+	}
+	b.saved = saved
+	return nil
+}
+
+// set writes key, value pairs of the Night Light schema in order.
+func (b *gnomeBackend) set(ctx context.Context, kv ...string) error {
+	for i := 0; i+1 < len(kv); i += 2 {
+		if out, err := b.u.exec(ctx, "gsettings", "set", nightLightSchema, kv[i], kv[i+1]); err != nil {
+			return commandError(out, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+const headlessWait = 2 * time.Second
+
+// headlessCheck is one end-to-end scenario. calls receives every command
+// the apply pipeline would have run.
+type headlessCheck struct {
+	name string
+	run  func(u *uiState, calls <-chan []string) error
+}
+
+var headlessChecks = []headlessCheck{
+	{"slider drags are debounced into one apply", func(u *uiState, calls <-chan []string) error {
+		fyne.DoAndWait(func() {
+			u.tempK.SetValue(5000)
+			u.tempK.SetValue(4500)
+			u.tempK.SetValue(4000)
+		})
+		if err := expectCall(calls, "-O", "4000"); err != nil {
+			return err
+		}
+		return expectNoCall(calls)
+	}},
+	{"gaming mode neutralizes gamma and restores it", func(u *uiState, calls <-chan []string) error {
+		fyne.DoAndWait(func() { u.gamma.SetValue(1.50) })
+		if err := expectCall(calls, "-g", "1.50:1.50:1.50"); err != nil {
+			return err
+		}
+		fyne.DoAndWait(u.toggleGaming)
+		if err := expectCall(calls, "-g", "1.00:1.00:1.00"); err != nil {
+			return err
+		}
+		fyne.DoAndWait(u.toggleGaming)
+		return expectCall(calls, "-g", "1.50:1.50:1.50")
+	}},
+	{"timed overrides expire on their own", func(u *uiState, calls <-chan []string) error {
+		fyne.DoAndWait(func() {
+			u.pushTimedOverride("Test", Settings{TempK: 3000, Brightness: 0.50, Gamma: 1.00}, 600*time.Millisecond)
+		})
+		if err := expectCall(calls, "-O", "3000"); err != nil {
+			return err
+		}
+		return expectCall(calls, "-O", "4000")
+	}},
+	{"reset clears the ramps and the sliders", func(u *uiState, calls <-chan []string) error {
+		go u.reset()
+		if err := expectCall(calls, "-x"); err != nil {
+			return err
+		}
+		// The sliders move on the UI thread after the command returns.
+		var got Settings
+		for deadline := time.Now().Add(headlessWait); time.Now().Before(deadline); time.Sleep(debounce / 5) {
+			if fyne.DoAndWait(func() { got = u.current() }); got == defaultSettings {
+				return nil
+			}
+		}
+		return fmt.Errorf("sliders at %+v after reset", got)
+	}},
+	{"the schedule applies the entry in effect", func(u *uiState, calls <-chan []string) error {
+		fyne.DoAndWait(func() {
+			u.cfg.ScheduleEntries = []ScheduleEntry{
+				{At: "00:00", Settings: Settings{TempK: 3300, Brightness: 0.80, Gamma: 1.00}},
+			}
+			u.setSchedule(true)
+		})
+		defer fyne.DoAndWait(func() { u.setSchedule(false) })
+		return expectCall(calls, "-O", "3300")
+	}},
+}
+
+// runHeadlessTest drives the real UI state with a recording stand-in for
+// redshift and prints one PASS/FAIL line per check. The window is never
+// shown, but the GLFW driver still needs a display: on CI either run it
+// under xvfb-run or build with -tags ci to use Fyne's software driver.
+// Returns the process exit code.
+func runHeadlessTest(a fyne.App, w fyne.Window) int {
+	// Keep config and history writes away from the user's files.
+	tmp, err := os.MkdirTemp("", "redshift-control-panel-test-")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer os.RemoveAll(tmp)
+	os.Setenv("XDG_CONFIG_HOME", filepath.Join(tmp, "config"))
+	os.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "data"))
+
+	calls := make(chan []string, 16)
+	u := newUI(w, defaultConfig())
+	u.seat = seatInfo{id: "seat0"}
+	u.chooseBackend(backendRedshift) // even under Wayland: the checks record redshift calls
+	u.run = func(_ context.Context, name string, args ...string) ([]byte, error) {
+		calls <- append([]string{name}, args...)
+		return nil, nil
+	}
+
+	result := make(chan int, 1)
+	go func() {
+		defer a.Quit()
+		failed := 0
+		for _, c := range headlessChecks {
+			if err := c.run(u, calls); err != nil {
+				failed++
+				fmt.Printf("FAIL %s: %v\n", c.name, err)
+			} else {
+				fmt.Printf("PASS %s\n", c.name)
+			}
+			drain(calls)
+		}
+		fmt.Printf("%d/%d checks passed\n", len(headlessChecks)-failed, len(headlessChecks))
+		result <- min(failed, 1)
+	}()
+	a.Run()
+	return <-result
+}
+
+// expectCall waits for the next command and checks that it contains want
+// as consecutive arguments.
+func expectCall(calls <-chan []string, want ...string) error {
+	select {
+	case got := <-calls:
+		for i := range got {
+			if slices.Equal(got[i:min(i+len(want), len(got))], want) {
+				return nil
+			}
+		}
+		return fmt.Errorf("ran %q, want %q in it", strings.Join(got, " "), strings.Join(want, " "))
+	case <-time.After(headlessWait):
+		return fmt.Errorf("nothing applied, want %q", strings.Join(want, " "))
+	}
+}
+
+func expectNoCall(calls <-chan []string) error {
+	select {
+	case got := <-calls:
+		return fmt.Errorf("unexpected extra apply %q", strings.Join(got, " "))
+	case <-time.After(2 * debounce):
+		return nil
+	}
+}
+
+func drain(calls <-chan []string) {
+	for {
+		select {
+		case <-calls:
+		case <-time.After(2 * debounce):
+			return
+		}
+	}
+}
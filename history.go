@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+)
+
+const (
+	historySampleEvery = 5 * time.Minute
+	historyRetention   = 365 * 24 * time.Hour
+	historyPruneEvery  = 24 * time.Hour
+)
+
+// historyMu keeps a prune from replacing the file under an append.
+var historyMu sync.Mutex
+
+// historyEntry is one line of the usage history: either a periodic sample
+// of what was on screen and which override, if any, put it there, or an
+// event such as an override being started.
+type historyEntry struct {
+	Time     time.Time `json:"t"`
+	Event    string    `json:"event,omitempty"` // "" for samples
+	Settings Settings  `json:"settings"`
+	Mode     string    `json:"mode,omitempty"`
+	Seconds  int       `json:"seconds,omitempty"` // how long a pause lasted
+}
+
+const (
+	eventOverride = "override"
+	eventPause    = "pause" // logged when a pause ends, at the time it began
+)
+
+// dataDir returns $XDG_DATA_HOME/redshift-control-panel.
+func dataDir() (string, error) {
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dir, "redshift-control-panel"), nil
+}
+
+func historyPath() (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "history.jsonl"), nil
+}
+
+func appendHistory(e historyEntry) error {
+	path, err := historyPath()
+	if err != nil {
+		return err
+	}
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readHistory returns every entry newer than since, skipping lines it
+// can't parse rather than failing the whole read.
+func readHistory(since time.Time) ([]historyEntry, error) {
+	path, err := historyPath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []historyEntry
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e historyEntry
+		if json.Unmarshal(sc.Bytes(), &e) == nil && e.Time.After(since) {
+			entries = append(entries, e)
+		}
+	}
+	return entries, sc.Err()
+}
+
+// pruneHistory drops entries older than historyRetention.
+func pruneHistory() error {
+	path, err := historyPath()
+	if err != nil {
+		return err
+	}
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	entries, err := readHistory(time.Now().Add(-historyRetention))
+	if err != nil {
+		return err
+	}
+	var buf []byte
+	for _, e := range entries {
+		data, _ := json.Marshal(e)
+		buf = append(append(buf, data...), '\n')
+	}
+	return writeFileAtomic(path, buf, 0o644)
+}
+
+func writeHistoryCSV(w io.Writer, entries []historyEntry) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = csvComma
+	cw.Write([]string{"time", "temp_k", "brightness", "gamma", "blue_reduction", "mode"})
+	for _, e := range entries {
+		if e.Event != "" {
+			continue
+		}
+		cw.Write([]string{
+			e.Time.Format(time.RFC3339),
+			strconv.Itoa(e.Settings.TempK),
+			csvNum(e.Settings.Brightness, 2),
+			csvNum(e.Settings.Gamma, 2),
+			csvNum(e.Settings.BlueReduction, 2),
+			e.Mode,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// setHistory starts or stops recording a sample of the applied settings
+// every historySampleEvery, pruning old ones every historyPruneEvery, and
+// persists the choice.
+func (u *uiState) setHistory(on bool) {
+	if on == (u.historyStop != nil) {
+		return
+	}
+	u.cfg.History = on
+	u.saveConfig()
+
+	if !on {
+		close(u.historyStop)
+		u.historyStop = nil
+		return
+	}
+	stop := make(chan struct{})
+	u.historyStop = stop
+	go func() {
+		prune := func() {
+			if err := pruneHistory(); err != nil {
+				fyne.Do(func() { u.out.SetText("History error: " + err.Error()) })
+			}
+		}
+		prune()
+		pruned := time.Now()
+		tick := time.NewTicker(historySampleEvery)
+		defer tick.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-tick.C:
+				fyne.Do(func() { u.recordSample() })
+				if time.Since(pruned) >= historyPruneEvery {
+					prune()
+					pruned = time.Now()
+				}
+			}
+		}
+	}()
+}
+
+func (u *uiState) recordSample() {
+	e := historyEntry{Time: time.Now(), Settings: u.target()}
+	if o, ok := u.activeOverride(); ok {
+		e.Mode = o.name
+	}
+	u.record(e)
+}
+
+// recordOverride logs that the override called name was started.
+func (u *uiState) recordOverride(name string, s Settings) {
+	if u.historyStop != nil {
+		u.record(historyEntry{Time: time.Now(), Event: eventOverride, Settings: s, Mode: name})
+	}
+}
+
+// recordOverrideEnd logs how long o held the schedule off, for pauses.
+func (u *uiState) recordOverrideEnd(o override) {
+	if u.historyStop != nil && o.name == pauseOverride {
+		u.record(historyEntry{Time: o.started, Event: eventPause, Mode: o.name, Seconds: int(time.Since(o.started).Seconds())})
+	}
+}
+
+func (u *uiState) record(e historyEntry) {
+	go func() {
+		if err := appendHistory(e); err != nil {
+			fyne.Do(func() { u.out.SetText("History error: " + err.Error()) })
+		}
+	}()
+}
+
+// exportHistory saves all recorded samples as CSV.
+func (u *uiState) exportHistory() {
+	entries, err := readHistory(time.Time{})
+	if err != nil {
+		u.out.SetText("Export error: " + err.Error())
+		return
+	}
+	if len(entries) == 0 {
+		u.out.SetText("No usage history recorded yet.")
+		return
+	}
+
+	d := dialog.NewFileSave(func(wc fyne.URIWriteCloser, err error) {
+		if err != nil || wc == nil {
+			return
+		}
+		defer wc.Close()
+		if err := writeHistoryCSV(wc, entries); err != nil {
+			u.out.SetText("Export error: " + err.Error())
+			return
+		}
+		u.out.SetText("Exported " + strconv.Itoa(len(entries)) + " samples to " + wc.URI().Name() + ".")
+	}, u.win)
+	d.SetFileName("usage-history.csv")
+	d.SetFilter(storage.NewExtensionFileFilter([]string{".csv"}))
+	d.Show()
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// hotkey is a global key combination grabbed on the X root window, so it
+// works whichever window has focus.
+type hotkey struct {
+	mods   uint16 // xproto.ModMask* bits
+	keysym xproto.Keysym
+	fire   func() // runs on the UI thread
+}
+
+// lockMasks are the modifiers that must not change a hotkey's meaning:
+// none, Caps Lock, Num Lock and both.
+var lockMasks = []uint16{0, xproto.ModMaskLock, xproto.ModMask2, xproto.ModMaskLock | xproto.ModMask2}
+
+// grabHotkeys grabs keys and dispatches their presses until the returned
+// stop function is called. Keys another client already grabbed are
+// skipped and reported in the error; the rest still work.
+func grabHotkeys(keys []hotkey) (stop func(), err error) {
+	X, err := xgb.NewConn()
+	if err != nil {
+		return nil, err
+	}
+	setup := xproto.Setup(X)
+	root := setup.DefaultScreen(X).Root
+	codes, err := keycodes(X, setup)
+	if err != nil {
+		X.Close()
+		return nil, err
+	}
+
+	type grabbed struct {
+		code xproto.Keycode
+		key  hotkey
+	}
+	var active []grabbed
+	var failed int
+	for _, k := range keys {
+		code, ok := codes[k.keysym]
+		if !ok {
+			failed++
+			continue
+		}
+		ok = true
+		for _, lock := range lockMasks {
+			err := xproto.GrabKeyChecked(X, true, root, k.mods|lock, code,
+				xproto.GrabModeAsync, xproto.GrabModeAsync).Check()
+			ok = ok && err == nil
+		}
+		if !ok {
+			failed++
+			continue
+		}
+		active = append(active, grabbed{code, k})
+	}
+	if failed > 0 {
+		err = fmt.Errorf("%d of %d hotkeys are taken by another program", failed, len(keys))
+	}
+
+	go func() {
+		for {
+			ev, xerr := X.WaitForEvent()
+			if ev == nil && xerr == nil {
+				return // connection closed by stop
+			}
+			kp, ok := ev.(xproto.KeyPressEvent)
+			if !ok {
+				continue
+			}
+			state := kp.State &^ (xproto.ModMaskLock | xproto.ModMask2)
+			for _, g := range active {
+				if g.code == kp.Detail && g.key.mods == state {
+					fyne.Do(g.key.fire)
+				}
+			}
+		}
+	}()
+	return X.Close, err
+}
+
+// keycodes maps every keysym on the current keyboard to the first keycode
+// producing it.
+func keycodes(X *xgb.Conn, setup *xproto.SetupInfo) (map[xproto.Keysym]xproto.Keycode, error) {
+	first, count := setup.MinKeycode, int(setup.MaxKeycode-setup.MinKeycode)+1
+	m, err := xproto.GetKeyboardMapping(X, first, byte(count)).Reply()
+	if err != nil {
+		return nil, err
+	}
+	per := int(m.KeysymsPerKeycode)
+	codes := map[xproto.Keysym]xproto.Keycode{}
+	for i, sym := range m.Keysyms {
+		code := xproto.Keycode(int(first) + i/per)
+		if _, seen := codes[sym]; sym != 0 && !seen {
+			codes[sym] = code
+		}
+	}
+	return codes, nil
+}
+
+// syncHotkeys re-grabs the global hotkeys to match the configuration.
+func (u *uiState) syncHotkeys() {
+	if u.hotkeysStop != nil {
+		u.hotkeysStop()
+		u.hotkeysStop = nil
+	}
+	keys := append(u.slotHotkeys(), u.brightnessHotkeys()...)
+	if len(keys) == 0 {
+		return
+	}
+	stop, err := grabHotkeys(keys)
+	u.hotkeysStop = stop
+	if err != nil {
+		u.out.SetText("Hotkeys: " + err.Error())
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/randr"
+	"github.com/jezek/xgb/xproto"
+)
+
+// hotplugLoop reapplies whenever RandR reports a monitor plugged in,
+// unplugged or reconfigured: a newly lit CRTC starts with a linear ramp,
+// and a new resolution or rotation resets the ramp on many drivers, and
+// redshift's one-shot settings don't follow either. DDC/CI monitors are
+// detected again too. It runs for the life of the app.
+func (u *uiState) hotplugLoop() {
+	X, err := xgb.NewConn()
+	if err != nil {
+		return
+	}
+	defer X.Close()
+	if err := randr.Init(X); err != nil {
+		return
+	}
+	root := xproto.Setup(X).DefaultScreen(X).Root
+	if err := randr.SelectInputChecked(X, root, randr.NotifyMaskScreenChange|randr.NotifyMaskCrtcChange|randr.NotifyMaskOutputChange).Check(); err != nil {
+		return
+	}
+	for {
+		ev, err := X.WaitForEvent()
+		if ev == nil && err == nil {
+			return // connection closed
+		}
+		switch ev.(type) {
+		case randr.ScreenChangeNotifyEvent, randr.NotifyEvent:
+		default:
+			continue
+		}
+		u.ddc.mu.Lock()
+		u.ddc.buses, u.ddc.last = nil, nil
+		u.ddc.mu.Unlock()
+		fyne.Do(func() {
+			switch {
+			case u.remote.Load() != nil:
+			case u.daemon.running():
+				u.restartRedshiftDaemon()
+			default:
+				u.scheduleApply(u.target()) // debounced, so a burst of events applies once
+			}
+		})
+	}
+}
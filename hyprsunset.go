@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const backendHyprsunset = "hyprsunset"
+
+// hyprlandSession reports whether we run under Hyprland, which has no
+// wlr gamma protocol for other tools but ships hyprsunset.
+func hyprlandSession() bool {
+	return os.Getenv("HYPRLAND_INSTANCE_SIGNATURE") != ""
+}
+
+// hyprsunsetBackend controls hyprsunset through hyprctl's IPC, starting
+// it first if it isn't running. hyprsunset's "gamma" is a brightness
+// percentage; it has no gamma curve.
+type hyprsunsetBackend struct {
+	u    *uiState
+	proc process
+}
+
+func (b *hyprsunsetBackend) Name() string { return backendHyprsunset }
+
+func (b *hyprsunsetBackend) Capabilities() Capabilities {
+	return Capabilities{Temperature: true, Brightness: true}
+}
+
+func (b *hyprsunsetBackend) Available() bool {
+	return hasCommand("hyprsunset") && hyprlandSession()
+}
+
+func (b *hyprsunsetBackend) Apply(ctx context.Context, s Settings) error {
+	temp, pct := fmt.Sprint(s.TempK), fmt.Sprintf("%.0f", s.Brightness*100)
+	if b.hyprctl(ctx, "temperature", temp) != nil {
+		// Not running, or too old for IPC: start our own.
+		return b.proc.restart(ctx, "hyprsunset", "-t", temp, "-g", pct)
+	}
+	return b.hyprctl(ctx, "gamma", pct)
+}
+
+// Reset stops the hyprsunset we started, which drops its gamma, or tells
+// the user's own one to go neutral.
+func (b *hyprsunsetBackend) Reset(ctx context.Context) error {
+	if b.proc.running() {
+		b.proc.stop()
+		return nil
+	}
+	return b.hyprctl(ctx, "identity")
+}
+
+// hyprctl sends a hyprsunset request; hyprctl answers "ok" when one was
+// handled, and exits 0 either way.
+func (b *hyprsunsetBackend) hyprctl(ctx context.Context, args ...string) error {
+	out, err := b.u.exec(ctx, "hyprctl", append([]string{"hyprsunset"}, args...)...)
+	if err != nil {
+		return commandError(out, err)
+	}
+	if msg := strings.TrimSpace(string(out)); msg != "ok" {
+		return errors.New(msg)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+const identifyFor = 3 * time.Second
+
+// identifyMonitors shows each monitor's number and name in big type on
+// that monitor for a few seconds, numbered like the Monitors tabs. Fyne
+// can't place a window, so each is found by its title and moved over X.
+func (u *uiState) identifyMonitors(outs []seatOutput) {
+	drv, ok := fyne.CurrentApp().Driver().(desktop.Driver)
+	if !ok {
+		return
+	}
+	var wins []fyne.Window
+	for i, o := range outs {
+		w := drv.CreateSplashWindow()
+		title := fmt.Sprintf("Identify monitor %d", i+1)
+		w.SetTitle(title)
+		number := canvas.NewText(fmt.Sprint(i+1), color.White)
+		number.TextSize = 140
+		number.TextStyle.Bold = true
+		number.Alignment = fyne.TextAlignCenter
+		name := canvas.NewText(o.label(), color.White)
+		name.TextSize = 24
+		name.Alignment = fyne.TextAlignCenter
+		bg := canvas.NewRectangle(color.NRGBA{R: 0x31, G: 0x31, B: 0x31, A: 0xFF})
+		w.SetContent(container.NewStack(bg, container.NewCenter(container.NewVBox(number, name))))
+		w.Resize(fyne.NewSize(420, 280))
+		w.Show()
+		wins = append(wins, w)
+		go centerWindow(title, o)
+	}
+	time.AfterFunc(identifyFor, func() {
+		fyne.Do(func() {
+			for _, w := range wins {
+				w.Close()
+			}
+		})
+	})
+}
+
+// centerWindow moves the top-level window titled title to the middle of
+// o, waiting a little for it to be mapped.
+func centerWindow(title string, o seatOutput) {
+	X, err := xgb.NewConn()
+	if err != nil {
+		return
+	}
+	defer X.Close()
+	root := xproto.Setup(X).DefaultScreen(X).Root
+	for try := 0; try < 20; try++ {
+		clients, _ := windowProperty32(X, root, "_NET_CLIENT_LIST")
+		for _, c := range clients {
+			win := xproto.Window(c)
+			if windowName(X, win) != title {
+				continue
+			}
+			g, err := xproto.GetGeometry(X, xproto.Drawable(win)).Reply()
+			if err != nil {
+				return
+			}
+			x := o.x + (o.width-int(g.Width))/2
+			y := o.y + (o.height-int(g.Height))/2
+			xproto.ConfigureWindow(X, win, xproto.ConfigWindowX|xproto.ConfigWindowY, []uint32{uint32(x), uint32(y)})
+			X.Sync()
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
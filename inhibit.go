@@ -0,0 +1,102 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	inhibitPollInterval  = 5 * time.Second
+	presentationOverride = "Presentation"
+)
+
+// setRespectInhibitors starts or stops watching for idle inhibitors, which
+// video calls and slideshows take out. While one is held the screen is
+// returned to neutral so colours look right to the audience.
+func (u *uiState) setRespectInhibitors(on bool) {
+	if on == (u.inhibitStop != nil) {
+		return
+	}
+	u.cfg.RespectInhibitors = on
+	u.saveConfig()
+
+	if !on {
+		close(u.inhibitStop)
+		u.inhibitStop = nil
+		u.popOverride(presentationOverride)
+		return
+	}
+	stop := make(chan struct{})
+	u.inhibitStop = stop
+	go u.inhibitLoop(stop)
+}
+
+func (u *uiState) inhibitLoop(stop chan struct{}) {
+	tick := time.NewTicker(inhibitPollInterval)
+	defer tick.Stop()
+	held := false
+	for {
+		now := idleInhibited()
+		if now != held {
+			held = now
+			fyne.Do(func() {
+				if u.inhibitStop != stop {
+					return
+				}
+				if now {
+					u.pushOverride(presentationOverride, defaultSettings)
+				} else {
+					u.popOverride(presentationOverride)
+				}
+			})
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-tick.C:
+		}
+	}
+}
+
+// idleInhibited reports whether some application is keeping the screen
+// awake. There is no single place to ask: GNOME keeps org.freedesktop.
+// ScreenSaver and portal inhibitors in its session manager, KDE in its
+// PowerManagement service, and other desktops forward them to logind.
+func idleInhibited() bool {
+	if conn, err := dbus.SessionBus(); err == nil {
+		const gsm = "org.gnome.SessionManager"
+		var inhibited bool
+		const inhibitIdle = 8
+		if conn.Object(gsm, "/org/gnome/SessionManager").Call(gsm+".IsInhibited", 0, uint32(inhibitIdle)).Store(&inhibited) == nil && inhibited {
+			return true
+		}
+		const pm = "org.freedesktop.PowerManagement"
+		if conn.Object(pm, "/org/freedesktop/PowerManagement/Inhibit").Call(pm+".Inhibit.HasInhibit", 0).Store(&inhibited) == nil && inhibited {
+			return true
+		}
+	}
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return false
+	}
+	// (what, who, why, mode, uid, pid)
+	var list []struct {
+		What, Who, Why, Mode string
+		UID, PID             uint32
+	}
+	err = conn.Object("org.freedesktop.login1", "/org/freedesktop/login1").
+		Call("org.freedesktop.login1.Manager.ListInhibitors", 0).Store(&list)
+	if err != nil {
+		return false
+	}
+	for _, in := range list {
+		if in.Mode == "block" && strings.Contains(in.What, "idle") {
+			return true
+		}
+	}
+	return false
+}
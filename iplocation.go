@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// ipLocationURL answers with the location of the address asking. It
+	// is only ever queried once: the answer is kept in Config.Location.
+	ipLocationURL = "https://ipapi.co/json/"
+	ipTimeout     = 10 * time.Second
+)
+
+// ipLocation estimates where we are from our public IP address. It is
+// often off by a city or two, which sunrise and sunset barely notice.
+func ipLocation(ctx context.Context) (Location, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ipLocationURL, nil)
+	if err != nil {
+		return Location{}, err
+	}
+	req.Header.Set("User-Agent", "redshift-control-panel")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return Location{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Location{}, fmt.Errorf("lookup failed: %s", resp.Status)
+	}
+	var body struct {
+		Lat    *float64 `json:"latitude"`
+		Lon    *float64 `json:"longitude"`
+		Reason string   `json:"reason"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return Location{}, err
+	}
+	if body.Lat == nil || body.Lon == nil {
+		if body.Reason != "" {
+			return Location{}, errors.New(body.Reason)
+		}
+		return Location{}, errors.New("no location in the answer")
+	}
+	return Location{Lat: *body.Lat, Lon: *body.Lon}, nil
+}
+
+// setIPLocation chooses whether Auto may fall back to looking our IP
+// address up online.
+func (u *uiState) setIPLocation(on bool) {
+	u.cfg.IPLocation = on
+	u.saveConfig()
+	if on && u.autoStop != nil {
+		u.locate()
+	}
+}
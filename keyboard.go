@@ -0,0 +1,117 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"github.com/godbus/dbus/v5"
+)
+
+const kbdUPower = "org.freedesktop.UPower.KbdBacklight"
+
+// kbdBrightness returns the keyboard backlight level and its maximum,
+// through UPower or else the kernel's kbd_backlight LED.
+func kbdBrightness() (level, max int, err error) {
+	if conn, err := dbus.SystemBus(); err == nil {
+		obj := conn.Object("org.freedesktop.UPower", "/org/freedesktop/UPower/KbdBacklight")
+		var l, m int32
+		if obj.Call(kbdUPower+".GetMaxBrightness", 0).Store(&m) == nil && obj.Call(kbdUPower+".GetBrightness", 0).Store(&l) == nil {
+			return int(l), int(m), nil
+		}
+	}
+	dir, ok := kbdLED()
+	if !ok {
+		return 0, 0, errors.New("no keyboard backlight found")
+	}
+	if max, err = readSysInt(filepath.Join(dir, "max_brightness")); err != nil {
+		return 0, 0, err
+	}
+	level, err = readSysInt(filepath.Join(dir, "brightness"))
+	return level, max, err
+}
+
+// setKbdBrightness sets the keyboard backlight level. UPower allows the
+// session to; the sysfs file usually needs root.
+func setKbdBrightness(level int) error {
+	conn, err := dbus.SystemBus()
+	if err == nil {
+		err = conn.Object("org.freedesktop.UPower", "/org/freedesktop/UPower/KbdBacklight").
+			Call(kbdUPower+".SetBrightness", 0, int32(level)).Err
+		if err == nil {
+			return nil
+		}
+	}
+	dir, ok := kbdLED()
+	if !ok {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "brightness"), []byte(strconv.Itoa(level)), 0o644)
+}
+
+// kbdLED returns the sysfs directory of the keyboard backlight LED.
+func kbdLED() (string, bool) {
+	dirs, _ := filepath.Glob("/sys/class/leds/*kbd_backlight*")
+	if len(dirs) == 0 {
+		return "", false
+	}
+	return dirs[0], true
+}
+
+// kbdState remembers the keyboard level from before we dimmed it.
+type kbdState struct {
+	mu     sync.Mutex
+	dimmed bool // we lowered it and owe a restore
+	before int
+}
+
+// applyKeyboard dims the keyboard backlight to Config.KeyboardLevel while
+// s is at or below Config.KeyboardNightK and puts it back above. It only
+// touches the backlight on crossing, so the user's own key presses in
+// between stick.
+func (u *uiState) applyKeyboard(s Settings) string {
+	if !u.kbdDim.Load() || u.remote.Load() != nil {
+		return ""
+	}
+	night := s.TempK <= u.cfg.KeyboardNightK
+	st := &u.kbd
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if night == st.dimmed {
+		return ""
+	}
+	level, max, err := kbdBrightness()
+	if err == nil && night {
+		st.before = level
+		err = setKbdBrightness(int(math.Round(u.cfg.KeyboardLevel * float64(max))))
+	} else if err == nil {
+		err = setKbdBrightness(st.before)
+	}
+	if err != nil {
+		return " Keyboard backlight error: " + err.Error() + "."
+	}
+	st.dimmed = night
+	return ""
+}
+
+// setKeyboardDim turns keyboard backlight dimming on or off. Turning it
+// off while dimmed restores the level.
+func (u *uiState) setKeyboardDim(on bool) {
+	u.cfg.KeyboardDim = on
+	u.saveConfig()
+	u.kbdDim.Store(on)
+	go func() {
+		st := &u.kbd
+		st.mu.Lock()
+		if !on && st.dimmed {
+			setKbdBrightness(st.before)
+			st.dimmed = false
+		}
+		st.mu.Unlock()
+		fyne.Do(func() { u.scheduleApply(u.target()) })
+	}()
+}
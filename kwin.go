@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const backendKWin = "kwin-night-color"
+
+// plasmaSession reports whether we run under KDE Plasma, whose KWin
+// manages the gamma itself and undoes anyone else's ramps.
+func plasmaSession() bool {
+	return strings.Contains(os.Getenv("XDG_CURRENT_DESKTOP"), "KDE")
+}
+
+// kwriteconfig returns the kwriteconfig binary for the installed Plasma
+// version, or "" if there is none.
+func kwriteconfig() string { return kconfigTool("kwriteconfig") }
+
+// kconfigTool returns kind's binary for the installed Plasma version, or
+// "" if there is none.
+func kconfigTool(kind string) string {
+	for _, name := range []string{kind + "6", kind + "5"} {
+		if hasCommand(name) {
+			return name
+		}
+	}
+	return ""
+}
+
+// kwinKeys are the [NightColor] keys Apply changes.
+var kwinKeys = []string{"Active", "Mode", "NightTemperature"}
+
+// kwinBackend drives KWin's own Night Color instead of fighting it: it
+// switches Night Color to a constant temperature in kwinrc and has KWin
+// reload its configuration. Night Color only does temperature. The
+// user's own settings are read before the first apply and put back by
+// Reset.
+type kwinBackend struct {
+	u     *uiState
+	mu    sync.Mutex
+	saved map[string]string // kwinrc before our first apply, "" for unset; nil when not taken over
+}
+
+func (b *kwinBackend) Name() string { return backendKWin }
+
+func (b *kwinBackend) Capabilities() Capabilities { return Capabilities{Temperature: true} }
+
+// Limits: Night Color keeps its temperature between 1000K and neutral.
+func (b *kwinBackend) Limits() Ranges {
+	l := redshiftLimits
+	l.TempMin, l.TempMax = 1000, 6500
+	return l
+}
+
+func (b *kwinBackend) Available() bool { return plasmaSession() && kwriteconfig() != "" }
+
+func (b *kwinBackend) Apply(ctx context.Context, s Settings) error {
+	if err := b.save(ctx); err != nil {
+		return err
+	}
+	return b.write(ctx, map[string]string{
+		"Active":           "true",
+		"Mode":             "Constant",
+		"NightTemperature": fmt.Sprint(s.TempK),
+	})
+}
+
+// Reset puts back the Night Color settings the user had before our first
+// apply. Without those it turns Night Color off.
+func (b *kwinBackend) Reset(ctx context.Context) error {
+	b.mu.Lock()
+	saved := b.saved
+	b.saved = nil
+	b.mu.Unlock()
+	if saved == nil {
+		return b.write(ctx, map[string]string{"Active": "false"})
+	}
+	return b.write(ctx, saved)
+}
+
+// save reads the user's [NightColor] settings, once per takeover.
+func (b *kwinBackend) save(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.saved != nil {
+		return nil
+	}
+	tool := kconfigTool("kreadconfig")
+	if tool == "" {
+		return errors.New("kreadconfig6 or kreadconfig5 not found")
+	}
+	saved := map[string]string{}
+	for _, k := range kwinKeys {
+		out, err := b.u.exec(ctx, tool, "--file", "kwinrc", "--group", "NightColor", "--key", k)
+		if err != nil {
+			return commandError(out, err)
+		}
+		saved[k] = strings.TrimSpace(string(out))
+	}
+	b.saved = saved
+	return nil
+}
+
+// write sets keys in kwinrc's [NightColor] group, deleting those set to
+// "", and tells KWin.
+func (b *kwinBackend) write(ctx context.Context, keys map[string]string) error {
+	tool := kwriteconfig()
+	if tool == "" {
+		return errors.New("kwriteconfig6 or kwriteconfig5 not found")
+	}
+	for k, v := range keys {
+		args := []string{"--file", "kwinrc", "--group", "NightColor", "--key", k, v}
+		if v == "" {
+			args[len(args)-1] = "--delete"
+		}
+		if out, err := b.u.exec(ctx, tool, args...); err != nil {
+			return commandError(out, err)
+		}
+	}
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return err
+	}
+	return conn.Object("org.kde.KWin", "/KWin").CallWithContext(ctx, "org.kde.KWin.reconfigure", 0).Err
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+// rememberDelay is how long the sliders have to rest before their values
+// are written out, so a drag or a fade is saved once at the end.
+const rememberDelay = 2 * time.Second
+
+// rememberSliders stores the sliders in Config.HostSettings for the
+// current target once they have stopped moving for rememberDelay.
+func (u *uiState) rememberSliders() {
+	if u.rememberTimer != nil {
+		u.rememberTimer.Stop()
+	}
+	u.rememberTimer = time.AfterFunc(rememberDelay, func() {
+		fyne.Do(func() {
+			key, s := hostKey(u.cfg.Remote), u.current()
+			if old, ok := u.cfg.HostSettings[key]; ok && old == s {
+				return
+			}
+			if u.cfg.HostSettings == nil {
+				u.cfg.HostSettings = map[string]Settings{}
+			}
+			u.cfg.HostSettings[key] = s
+			u.saveConfig()
+		})
+	})
+}
+
+// restoreSliders puts the sliders back where the last run left them and,
+// with Config.ReapplyOnLaunch, on screen too, unless the redshift daemon
+// is about to take the screen.
+func (u *uiState) restoreSliders() {
+	s, ok := u.cfg.HostSettings[hostKey(u.cfg.Remote)]
+	if !ok {
+		return
+	}
+	u.setSliders(u.ranges().clamp(s))
+	if u.cfg.ReapplyOnLaunch && !u.cfg.RedshiftDaemon && s != defaultSettings {
+		u.scheduleApply(u.target())
+	}
+}
+
+func (u *uiState) setReapplyOnLaunch(on bool) {
+	u.cfg.ReapplyOnLaunch = on
+	u.saveConfig()
+}
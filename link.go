@@ -0,0 +1,60 @@
+package main
+
+import (
+	"math"
+	"slices"
+)
+
+// CurvePoint is one point of the temperature→brightness link curve.
+type CurvePoint struct {
+	TempK      int     `json:"temp_k"`
+	Brightness float64 `json:"brightness"`
+}
+
+// defaultLinkCurve dims gently as the screen warms.
+var defaultLinkCurve = []CurvePoint{
+	{TempK: 6500, Brightness: 1.00},
+	{TempK: 3400, Brightness: 0.80},
+	{TempK: 1000, Brightness: 0.55},
+}
+
+// linkedBrightness interpolates curve linearly at tempK, holding the end
+// values beyond its range.
+func linkedBrightness(curve []CurvePoint, tempK float64) float64 {
+	if len(curve) == 0 {
+		return 1.00
+	}
+	pts := slices.Clone(curve)
+	slices.SortFunc(pts, func(a, b CurvePoint) int { return a.TempK - b.TempK })
+	if tempK <= float64(pts[0].TempK) {
+		return pts[0].Brightness
+	}
+	for i := 1; i < len(pts); i++ {
+		a, b := pts[i-1], pts[i]
+		if tempK <= float64(b.TempK) {
+			t := (tempK - float64(a.TempK)) / float64(b.TempK-a.TempK)
+			return math.Round((a.Brightness+t*(b.Brightness-a.Brightness))*100) / 100
+		}
+	}
+	return pts[len(pts)-1].Brightness
+}
+
+func (u *uiState) setLinkBrightness(on bool) {
+	u.cfg.LinkBrightness = on
+	u.saveConfig()
+	if on {
+		u.out.SetText("Brightness now follows temperature.")
+	}
+}
+
+// followTemperature moves the brightness slider along the link curve
+// without an apply of its own; the temperature change applies both.
+func (u *uiState) followTemperature(tempK float64) {
+	if !u.cfg.LinkBrightness || u.silence {
+		return
+	}
+	u.silence = true
+	r := u.ranges()
+	u.brightness.SetValue(math.Min(math.Max(linkedBrightness(u.cfg.LinkCurve, tempK), r.BrightnessMin), r.BrightnessMax))
+	u.silence = false
+}
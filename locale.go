@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2/lang"
+)
+
+// commaLanguages write decimals with a comma.
+var commaLanguages = map[string]bool{
+	"bg": true, "ca": true, "cs": true, "da": true, "de": true, "el": true, "es": true,
+	"et": true, "eu": true, "fi": true, "fr": true, "gl": true, "hr": true, "hu": true,
+	"id": true, "is": true, "it": true, "lt": true, "lv": true, "nb": true, "nl": true,
+	"nn": true, "no": true, "pl": true, "pt": true, "ro": true, "ru": true, "sk": true,
+	"sl": true, "sr": true, "sv": true, "tr": true, "uk": true, "vi": true,
+}
+
+// decimalComma is whether the user's locale uses a decimal comma.
+var decimalComma = func() bool {
+	code, _, _ := strings.Cut(lang.SystemLocale().LanguageString(), "-")
+	return commaLanguages[strings.ToLower(code)]
+}()
+
+// csvComma is the CSV field separator for the user's locale: where the
+// comma is the decimal mark, spreadsheets expect a semicolon.
+var csvComma = func() rune {
+	if decimalComma {
+		return ';'
+	}
+	return ','
+}()
+
+// csvNum formats v with prec decimals for a CSV export in the user's
+// locale, to go with csvComma.
+func csvNum(v float64, prec int) string {
+	s := strconv.FormatFloat(v, 'f', prec, 64)
+	if decimalComma {
+		s = strings.Replace(s, ".", ",", 1)
+	}
+	return s
+}
+
+// formatNum formats v for display in the user's locale. JSON files and
+// command lines keep using a point; CSV exports go through csvNum.
+func formatNum(format string, v float64) string {
+	s := fmt.Sprintf(format, v)
+	if decimalComma {
+		s = strings.Replace(s, ".", ",", 1)
+	}
+	return s
+}
+
+// parseNum parses a number typed by the user, accepting either a decimal
+// point or a decimal comma whatever the locale.
+func parseNum(s string) (float64, error) {
+	return strconv.ParseFloat(strings.Replace(strings.TrimSpace(s), ",", ".", 1), 64)
+}
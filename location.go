@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// defaultLocationPrecision is how coarse stored coordinates are, in
+// degrees. 0.5° is roughly 50km: plenty for sunrise and sunset times,
+// useless for finding someone's street.
+const defaultLocationPrecision = 0.5
+
+// Location is a latitude/longitude pair in degrees.
+type Location struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// coarse rounds l to the nearest multiple of precision degrees. A
+// precision of zero or less keeps l as is.
+func (l Location) coarse(precision float64) Location {
+	if precision <= 0 {
+		return l
+	}
+	round := func(v float64) float64 {
+		return math.Round(math.Round(v/precision)*precision*1e6) / 1e6
+	}
+	return Location{Lat: round(l.Lat), Lon: round(l.Lon)}
+}
+
+// String formats l for display and logs. Locations are only ever held in
+// their coarse form, so this never reveals more than the configured
+// precision.
+func (l Location) String() string {
+	ns, ew := "N", "E"
+	if l.Lat < 0 {
+		ns = "S"
+	}
+	if l.Lon < 0 {
+		ew = "W"
+	}
+	return fmt.Sprintf("%g°%s %g°%s", math.Abs(l.Lat), ns, math.Abs(l.Lon), ew)
+}
+
+// setLocation stores l rounded to the configured precision; every source
+// of coordinates goes through here so nothing precise reaches the disk.
+func (u *uiState) setLocation(l Location) {
+	c := l.coarse(u.cfg.LocationPrecision)
+	u.cfg.Location = &c
+	u.saveConfig()
+	u.locationChanged()
+}
+
+// locationChanged restarts whatever follows the sun from where we are.
+func (u *uiState) locationChanged() {
+	if u.scheduleStop != nil {
+		u.restartSchedule()
+	}
+	if u.autoStop != nil {
+		u.restartAuto()
+	}
+}
+
+// locate updates the stored location for Auto: from GeoClue when
+// allowed, else from our IP address if the user opted in and no location
+// is known yet. An active place or a location typed in is taken as the
+// user saying where they are, so it is left alone.
+func (u *uiState) locate() {
+	useGeoClue := u.cfg.GeoClue
+	useIP := u.cfg.IPLocation && u.cfg.Location == nil // looked up once, then kept
+	if u.cfg.ActivePlace != "" || u.cfg.ManualLocation || !useGeoClue && !useIP {
+		return
+	}
+	go func() {
+		// Each source gets its own time: GeoClue often waits out all of
+		// its own without a fix.
+		lookup := func(find func(context.Context) (Location, error), d time.Duration) (Location, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), d)
+			defer cancel()
+			return find(ctx)
+		}
+		var l Location
+		var source string
+		err := errNoLocation
+		if useGeoClue {
+			l, err = lookup(geoclueLocation, geoclueTimeout)
+			source = "GeoClue"
+		}
+		if err != nil && useIP {
+			l, err = lookup(ipLocation, ipTimeout)
+			source = "Your IP address"
+		}
+		fyne.Do(func() {
+			if u.autoStop == nil {
+				return
+			}
+			if err != nil {
+				if _, ok := u.location(); !ok {
+					u.out.SetText(source + ": " + err.Error() + ". Auto needs a location; set one under Places › Location…")
+				}
+				return
+			}
+			u.setLocation(l)
+			u.out.SetText(source + " puts you near " + u.cfg.Location.String() + ".")
+		})
+	}()
+}
+
+func parseLocation(lat, lon string) (Location, error) {
+	var l Location
+	var err error
+	if l.Lat, err = parseNum(lat); err != nil || l.Lat < -90 || l.Lat > 90 {
+		return l, errors.New("latitude must be between -90 and 90")
+	}
+	if l.Lon, err = parseNum(lon); err != nil || l.Lon < -180 || l.Lon > 180 {
+		return l, errors.New("longitude must be between -180 and 180")
+	}
+	return l, nil
+}
+
+// showLocation lets the user say where they are, by coordinates or from
+// the offline city list, for those who allow neither GeoClue nor the IP
+// lookup. Clearing both fields hands the location back to them.
+func (u *uiState) showLocation() {
+	lat := widget.NewEntry()
+	lat.SetPlaceHolder("e.g. " + formatNum("%.1f", 52.5))
+	lon := widget.NewEntry()
+	lon.SetPlaceHolder("e.g. " + formatNum("%.1f", 13.4))
+	if l := u.cfg.Location; l != nil && u.cfg.ManualLocation {
+		lat.SetText(formatNum("%g", l.Lat))
+		lon.SetText(formatNum("%g", l.Lon))
+	}
+	names := make([]string, len(cities))
+	for i, c := range cities {
+		names[i] = c.name
+	}
+	city := widget.NewSelect(names, func(name string) {
+		for _, c := range cities {
+			if c.name == name {
+				lat.SetText(formatNum("%g", c.loc.Lat))
+				lon.SetText(formatNum("%g", c.loc.Lon))
+			}
+		}
+	})
+	city.PlaceHolder = "Pick a nearby city"
+	coords := widget.NewFormItem("Latitude", lat)
+	coords.HintText = "Leave both empty to find it automatically"
+
+	dialog.ShowForm("Location", "Set", "Cancel", []*widget.FormItem{
+		widget.NewFormItem("City", city),
+		coords,
+		widget.NewFormItem("Longitude", lon),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+		if strings.TrimSpace(lat.Text) == "" && strings.TrimSpace(lon.Text) == "" {
+			u.cfg.ManualLocation = false
+			u.cfg.Location = nil
+			u.saveConfig()
+			u.out.SetText("Location will be found automatically.")
+			u.locationChanged()
+			if u.autoStop != nil {
+				u.locate()
+			}
+			return
+		}
+		l, err := parseLocation(lat.Text, lon.Text)
+		if err != nil {
+			u.out.SetText("Location not set: " + err.Error())
+			return
+		}
+		u.cfg.ManualLocation = true
+		u.setLocation(l)
+		u.out.SetText("Location set to " + u.cfg.Location.String() + ".")
+	}, u.win)
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// lockFile is a no-op here; the atomic rename in writeFileAtomic still
+// keeps readers from seeing half a file.
+func lockFile(*os.File, bool) error { return nil }
@@ -2,16 +2,23 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"image/color"
+	"maps"
+	"math"
+	"os"
 	"os/exec"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 )
@@ -22,15 +29,89 @@ const (
 )
 
 type uiState struct {
+	win        fyne.Window
 	tempK      *LabeledSlider
 	brightness *LabeledSlider
 	gamma      *LabeledSlider
+	blue       *LabeledSlider
+	tint       *LabeledSlider
 	out        *widget.Label
+	mode       *widget.Label // active overrides, right of the status line
 	resetBtn   *widget.Button
-
-	timer   *time.Timer
-	cancel  context.CancelFunc
-	silence bool // prevent handlers when changing sliders programmatically
+	menuBtn    *widget.Button
+	gamingBtn  *widget.Button
+	header     *fyne.Container // header bar buttons, see buildToolbar
+	chips      *fyne.Container // preset buttons in the header, nil when not shown
+
+	profiles      []*widget.Button  // activity profiles above the sliders, see newProfileBar
+	profileSelect *widget.Select    // profile dropdown in the header, nil when not shown
+	panel         fyne.CanvasObject // the sliders, hidden in compact mode
+
+	cfg          Config
+	seat         seatInfo
+	base         atomic.Pointer[Ramp]   // imported calibration, nil when none
+	backends     []Backend              // what Config.Backend can pick, see newBackends
+	chosen       atomic.Int32           // index of the chosen backend
+	ramps        *rampBackend           // also the fallback for what the chosen one can't show
+	overlay      *overlayBackend        // dims for backends that can't
+	method       atomic.Pointer[string] // Config.Method
+	custom       atomic.Pointer[string] // Config.CustomCommand
+	ddcMode      atomic.Pointer[string] // Config.DDC
+	ddc          ddcState
+	blMode       atomic.Pointer[string] // Config.Backlight
+	bl           backlightState
+	kbdDim       atomic.Bool // Config.KeyboardDim
+	kbd          kbdState
+	verify       atomic.Pointer[verifyTarget] // what the last apply should have left in the ramps
+	remote       atomic.Pointer[Remote]       // machine to drive over SSH, nil for this one
+	remoteTools  sync.Map                     // "host command" → whether the remote has it, see remoteLacks
+	dither       atomic.Bool                  // dither native ramps at low brightness
+	reference    atomic.Int32                 // refCurve the native ramps are computed in
+	ditherMu     sync.Mutex
+	ditherStop   chan struct{} // non-nil while ramps are being dithered; guarded by ditherMu
+	ditherDone   chan struct{} // closed when the dither loop has exited
+	nudge        nudge         // content-adaptive offset
+	adaptiveStop chan struct{} // non-nil while adaptive mode runs
+	movieStop    chan struct{} // non-nil while movie mode runs
+	breakStop    chan struct{} // non-nil while break reminders run
+	historyStop  chan struct{} // non-nil while usage history is recorded
+	summaryStop  chan struct{} // non-nil while weekly summaries are on
+	ssidStop     chan struct{} // non-nil while Wi-Fi picks the place
+	focusStop    chan struct{} // non-nil while focus emphasis runs
+	inhibitStop  chan struct{} // non-nil while idle inhibitors are watched
+	triggerStop  chan struct{} // non-nil while the trigger directory is watched
+	scheduleStop chan struct{} // non-nil while the schedule is followed
+	autoStop     chan struct{} // non-nil while Auto follows the sun
+	wakeStop     chan struct{} // non-nil while the sunrise simulation waits for alarms
+	wakeFrom     Settings      // where the running sunrise ramp started
+	whiteX       float64       // xy white point replacing the temperature slider, 0 when unset
+	whiteY       float64
+	focused      atomic.Int32  // focused CRTC index + 1, 0 when not emphasizing
+	xScreen      atomic.Int32  // Config.XScreen + 1, 0 for every X screen
+	hotkeysStop  func()        // releases the global hotkeys, nil when none
+	trayPreset   string        // preset checked in the tray menu
+	overrides    []override    // temporary settings, newest last
+	modeStop     chan struct{} // non-nil while a countdown is shown
+	fadeStop     chan struct{} // non-nil while the sliders fade to a preset
+	daemon       process       // redshift in continual mode, see Config.RedshiftDaemon
+	daemonHold   *time.Timer   // non-nil while the sliders keep the daemon off
+	daemonHeld   atomic.Bool   // the sliders or an override keep the daemon off
+	cronStop     chan struct{} // non-nil while cron rules are followed
+	upcoming     upcoming      // next change Auto or the schedule will make
+
+	outputs atomic.Pointer[map[string]OutputAdjust] // Config.Outputs; the pointer is never nil
+	limits  atomic.Pointer[Ranges]                  // u.ranges() as of the last applyRanges
+	unfocus atomic.Pointer[focusShift]              // Config.FocusWarmK and FocusDim
+
+	pomodoroTimer *time.Timer   // next phase change, nil when stopped
+	pomodoroRun   int           // bumped on stop so stale timers are ignored
+	rememberTimer *time.Timer   // saves the sliders once they rest, see rememberSliders
+	outputsTimer  *time.Timer   // saves the monitor shifts once they rest, see saveOutputs
+	warned        string        // fields and reasons validate last logged
+	run           commandRunner // spawns redshift; swapped out by --headless-test
+	timer         *time.Timer
+	cancel        context.CancelFunc
+	silence       bool // prevent handlers when changing sliders programmatically
 }
 
 // ---- Custom theme for app-wide background (#313131) ----
@@ -48,43 +129,172 @@ func (t bgTheme) Color(name fyne.ThemeColorName, v fyne.ThemeVariant) color.Colo
 // -------------------------------------------------------
 
 func main() {
-	a := app.New()
+	headless := flag.Bool("headless-test", false, "run the apply pipeline against a dummy backend, report and exit")
+	flag.Parse()
+
+	a := app.NewWithID("com.oriole.redshiftcontrolpanel") // ID names us in notifications
 	a.Settings().SetTheme(bgTheme{Theme: theme.DefaultTheme()})
 
 	w := a.NewWindow("Screen Dimmer")
-	w.Resize(fyne.NewSize(400, 320))
+	w.Resize(fyne.NewSize(400, 380))
+
+	if *headless {
+		os.Exit(runHeadlessTest(a, w))
+	}
+
+	cfg, cfgErr := loadConfig()
+	cfg, envErr := cfg.applyEnv(os.Environ())
+	if cfgErr == nil {
+		cfgErr = envErr
+	}
+	u := newUI(w, cfg)
+	u.seat = detectSeat()
+	if cfg.WindowWidth > 0 && cfg.WindowHeight > 0 {
+		w.Resize(fyne.NewSize(cfg.WindowWidth, cfg.WindowHeight))
+	}
+	w.SetOnClosed(u.saveWindowSize)
+	u.out.SetText(u.backendStatus())
+	switch { // one message; a broken config matters most
+	case cfgErr != nil:
+		u.out.SetText("Could not load settings: " + cfgErr.Error())
+	case u.cfg.Backend != "":
+		// picked by hand, so no hints
+	case plasmaSession() && u.backend().Name() != backendKWin:
+		u.out.SetText("Plasma detected. If KWin keeps undoing the changes, pick Backend › kwin-night-color from the menu.")
+	case gnomeSession() && u.backend().Name() != backendGNOME:
+		u.out.SetText("GNOME detected. If Night Light conflicts, pick Backend › gnome-night-light from the menu.")
+	case nvidiaDetected():
+		u.out.SetText("NVIDIA driver detected. If colours don't change, try Backend › nvidia-settings from the menu.")
+	}
+	u.restoreSliders()
+	if u.cfg.Adaptive {
+		u.setAdaptive(true)
+	}
+	if u.cfg.MovieMode {
+		u.setMovieMode(true)
+	}
+	if u.cfg.Breaks {
+		u.setBreaks(true)
+	}
+	if u.cfg.RespectInhibitors {
+		u.setRespectInhibitors(true)
+	}
+	if u.cfg.Schedule {
+		u.setSchedule(true)
+	}
+	if u.cfg.Auto {
+		u.setAuto(true)
+	}
+	if u.cfg.RedshiftDaemon {
+		u.setRedshiftDaemon(true)
+	}
+	if u.cfg.WakeRamp {
+		u.setWakeRamp(true)
+	}
+	if u.cfg.FileTriggers {
+		u.setFileTriggers(true)
+	}
+	if u.cfg.FocusEmphasis {
+		u.setFocusEmphasis(true)
+	}
+	if u.cfg.History {
+		u.setHistory(true)
+	}
+	if u.cfg.WeeklySummary {
+		u.setSummary(true)
+	}
+	u.syncSSIDWatch()
+	u.syncCron()
+	u.syncHotkeys()
+	u.syncTray()
+	go u.verifyLoop()
+	go u.hotplugLoop()
+	go u.resumeLoop()
+	if err := u.startDBusAPI(); err != nil {
+		u.out.SetText("D-Bus API unavailable: " + err.Error())
+	}
 
+	w.ShowAndRun()
+}
+
+// newUI builds the window content around cfg. Nothing is applied and no
+// background features are started yet.
+func newUI(w fyne.Window, cfg Config) *uiState {
 	out := widget.NewLabel("Ready.")
+	mode := widget.NewLabel("")
+	mode.Importance = widget.HighImportance
 
 	// Build our reusable sliders
 	temp := NewLabeledSlider("Temperature (K)", 1000, 10000, 100, 6500, "%.0f", "K")
 	bright := NewLabeledSlider("Brightness", 0.10, 1.00, 0.01, 1.00, "%.2f", "")
 	gamma := NewLabeledSlider("Gamma", 0.50, 2.50, 0.01, 1.00, "%.2f", "")
-
-	u := &uiState{tempK: temp, brightness: bright, gamma: gamma, out: out}
-u.resetBtn = widget.NewButtonWithIcon("Reset to defaults", theme.ViewRefreshIcon(), func() { go u.reset() })
-
+	blue := NewLabeledSlider("Blue reduction", 0, 80, 1, 0, "%.0f", "%")
+	tint := NewLabeledSlider("Tint (− magenta, + green)", -tintMax, tintMax, 0.001, 0, "%+.3f", "Duv")
+
+	u := &uiState{win: w, cfg: cfg, run: runCommand,
+		tempK: temp, brightness: bright, gamma: gamma, blue: blue, tint: tint, out: out, mode: mode}
+	u.applySteps()
+	u.ramps = &rampBackend{u: u}
+	u.overlay = &overlayBackend{u: u}
+	u.backends = newBackends(u)
+	u.chooseBackend(cfg.Backend)
+	u.method.Store(&cfg.Method)
+	u.custom.Store(&cfg.CustomCommand)
+	u.ddcMode.Store(&cfg.DDC)
+	u.blMode.Store(&cfg.Backlight)
+	u.kbdDim.Store(cfg.KeyboardDim)
+	outputs := maps.Clone(cfg.Outputs)
+	u.outputs.Store(&outputs)
+	u.unfocus.Store(&focusShift{cfg.FocusWarmK, cfg.FocusDim})
+	u.remote.Store(cfg.Remote)
+	if cfg.XScreen != nil {
+		u.xScreen.Store(int32(*cfg.XScreen + 1))
+	}
+	u.dither.Store(cfg.Dither)
+	for ref, name := range refCurveNames {
+		if name == cfg.Reference {
+			u.reference.Store(int32(ref))
+		}
+	}
+	u.applyRanges() // needs the backend and the remote
+	u.resetBtn = widget.NewButtonWithIcon("Reset to defaults", theme.ViewRefreshIcon(), func() { go u.reset() })
+	u.gamingBtn = widget.NewButton("Gaming", u.toggleGaming)
+	u.menuBtn = widget.NewButtonWithIcon("", theme.MenuIcon(), func() { u.showMenu(u.menuBtn, u.headerMenu()) })
 	// Debounced live apply while dragging (snapshot values on UI thread)
 	onChange := func() {
 		if u.silence {
 			return
 		}
-		t := int(temp.Value())
-		b := bright.Value()
-		g := gamma.Value()
-		u.scheduleApply(t, b, g)
+		u.stopFade()
+		u.clearOverrides()
+		u.holdDaemon()
+		u.scheduleApply(u.target())
+		if u.activePreset() != u.trayPreset {
+			u.syncTray()
+		}
+		u.syncProfiles()
 	}
-	temp.SetOnChanged(func(_ float64) { onChange() })
+	temp.SetOnChanged(func(v float64) {
+		if !u.silence {
+			u.setWhite(0, 0) // dragging Kelvin leaves the xy white point
+		}
+		u.followTemperature(v)
+		onChange()
+	})
 	bright.SetOnChanged(func(_ float64) { onChange() })
 	gamma.SetOnChanged(func(_ float64) { onChange() })
+	blue.SetOnChanged(func(_ float64) { onChange() })
+	tint.SetOnChanged(func(_ float64) { onChange() })
+	u.syncControls()
 
 	// ----- Header bar (#494949) -----
-	headerContent := container.NewHBox(u.resetBtn)
+	u.header = container.NewHBox()
+	u.buildToolbar()
 
 	headerBG := canvas.NewRectangle(color.NRGBA{R: 0x49, G: 0x49, B: 0x49, A: 0xFF}) // #494949
 	header := container.NewStack(
 		headerBG,
-		container.NewPadded(headerContent), // nice inner spacing
+		container.NewPadded(u.header), // nice inner spacing
 	)
 
 	// ----- Settings panel -----
@@ -95,33 +305,217 @@ u.resetBtn = widget.NewButtonWithIcon("Reset to defaults", theme.ViewRefreshIcon
 		temp.View(),
 		thinDivider(color.NRGBA{R: 0x64, G: 0x64, B: 0x64, A: 0xFF}),
 		gamma.View(),
+		thinDivider(color.NRGBA{R: 0x64, G: 0x64, B: 0x64, A: 0xFF}),
+		blue.View(),
+		thinDivider(color.NRGBA{R: 0x64, G: 0x64, B: 0x64, A: 0xFF}),
+		tint.View(),
 	)
 	panelPadded := inset(panelInner, 10, 10, 10, 10)
 
 	panelBG := newRoundRect(
 		color.NRGBA{R: 0x41, G: 0x41, B: 0x41, A: 0xFF}, // fill #414141
 		color.NRGBA{R: 0x37, G: 0x37, B: 0x37, A: 0xFF}, // stroke #373737
-		1.0,                                              // stroke width
-		15,                                               // corner radius
+		1.0, // stroke width
+		15,  // corner radius
 	)
 
 	settingsPanel := container.NewStack(panelBG, panelPadded)
+	u.panel = container.NewPadded(settingsPanel)
+	if cfg.Compact {
+		u.panel.Hide()
+	}
 
 	// ----- Page content -----
 	w.SetContent(container.NewVBox(
 		header,
-		container.NewPadded(settingsPanel),
-		out,
+		container.NewPadded(u.newProfileBar()),
+		u.panel,
+		container.NewBorder(nil, nil, nil, mode, out),
 	))
 
-	if _, err := exec.LookPath("redshift"); err != nil {
-		out.SetText("Error: 'redshift' not found in PATH. Install it (e.g., sudo apt install redshift).")
+	// Ctrl+G toggles Gaming mode while the window has focus
+	w.Canvas().AddShortcut(&desktop.CustomShortcut{KeyName: fyne.KeyG, Modifier: fyne.KeyModifierShortcutDefault},
+		func(fyne.Shortcut) { u.toggleGaming() })
+
+	return u
+}
+
+// headerMenu builds the header menu, reflecting the current toggles.
+func (u *uiState) headerMenu() *fyne.Menu {
+	adaptive := fyne.NewMenuItem("Adapt to screen content", func() { u.setAdaptive(u.adaptiveStop == nil) })
+	adaptive.Checked = u.adaptiveStop != nil
+	movie := fyne.NewMenuItem("Movie mode during video playback", func() { u.setMovieMode(u.movieStop == nil) })
+	movie.Checked = u.movieStop != nil
+	breaks := fyne.NewMenuItem("Eye-break reminders (20-20-20)", func() { u.setBreaks(u.breakStop == nil) })
+	breaks.Checked = u.breakStop != nil
+	breakDim := fyne.NewMenuItem("Dim screen during breaks", func() { u.setBreakDim(!u.cfg.BreakDim) })
+	breakDim.Checked = u.cfg.BreakDim
+	history := fyne.NewMenuItem("Record usage history", func() { u.setHistory(u.historyStop == nil) })
+	history.Checked = u.historyStop != nil
+	summary := fyne.NewMenuItem("Weekly summary notification", func() { u.setSummary(u.summaryStop == nil) })
+	summary.Checked = u.summaryStop != nil
+	pomodoro := fyne.NewMenuItem("Start Pomodoro", u.togglePomodoro)
+	if u.pomodoroTimer != nil {
+		pomodoro.Label = "Stop Pomodoro"
+	}
+	places := fyne.NewMenuItem("Places", nil)
+	places.ChildMenu = u.placesMenu()
+	slots := fyne.NewMenuItem("Hotkey slots", nil)
+	slots.ChildMenu = u.slotsMenu()
+	brightKeys := fyne.NewMenuItem("Handle brightness keys", func() { u.setBrightnessKeys(!u.cfg.BrightnessKeys) })
+	brightKeys.Checked = u.cfg.BrightnessKeys
+	quick := fyne.NewMenuItem("Quick actions", nil)
+	quick.ChildMenu = u.quickMenu()
+	presets := fyne.NewMenuItem("Presets", nil)
+	presets.ChildMenu = u.presetsMenu()
+	pause := fyne.NewMenuItem("Pause", nil)
+	pause.ChildMenu = u.pauseMenu()
+	windDown := fyne.NewMenuItem("Wind down", nil)
+	windDown.ChildMenu = u.windDownMenu()
+	daemon := fyne.NewMenuItem("redshift daemon", nil)
+	daemon.ChildMenu = u.daemonMenu()
+	schedule := fyne.NewMenuItem("Follow schedule", func() { u.setSchedule(u.scheduleStop == nil) })
+	schedule.Checked = u.scheduleStop != nil
+	auto := fyne.NewMenuItem("Auto day and night", func() { u.setAuto(u.autoStop == nil) })
+	auto.Checked = u.autoStop != nil
+	wake := fyne.NewMenuItem(fmt.Sprintf("Sunrise %d min before the alarm", u.cfg.WakeMinutes), func() { u.setWakeRamp(u.wakeStop == nil) })
+	wake.Checked = u.wakeStop != nil
+	link := fyne.NewMenuItem("Dim as temperature warms", func() { u.setLinkBrightness(!u.cfg.LinkBrightness) })
+	link.Checked = u.cfg.LinkBrightness
+	focus := fyne.NewMenuItem("Emphasize the focused monitor", func() { u.setFocusEmphasis(u.focusStop == nil) })
+	focus.Checked = u.focusStop != nil
+	monitors := fyne.NewMenuItem("Monitors…", u.showMonitors)
+	if !u.offered().PerOutput { // still let emphasis be turned off
+		focus.Disabled, monitors.Disabled = !focus.Checked, true
 	}
+	inhibit := fyne.NewMenuItem("Neutral while presenting", func() { u.setRespectInhibitors(u.inhibitStop == nil) })
+	inhibit.Checked = u.inhibitStop != nil
+	triggers := fyne.NewMenuItem("Watch trigger files", func() { u.setFileTriggers(u.triggerStop == nil) })
+	triggers.Checked = u.triggerStop != nil
+	backend := fyne.NewMenuItem("Backend", nil)
+	backend.ChildMenu = u.backendMenu()
+	ddc := fyne.NewMenuItem("Monitor brightness (DDC/CI)", nil)
+	ddc.ChildMenu = hardwareMenu(u.cfg.DDC, u.setDDC)
+	laptop := fyne.NewMenuItem("Laptop backlight", nil)
+	laptop.ChildMenu = hardwareMenu(u.cfg.Backlight, u.setBacklightMode)
+	keyboard := fyne.NewMenuItem("Dim keyboard backlight at night", func() { u.setKeyboardDim(!u.cfg.KeyboardDim) })
+	keyboard.Checked = u.cfg.KeyboardDim
+	hosts := fyne.NewMenuItem("Screens", nil)
+	hosts.ChildMenu = u.hostsMenu()
+	xScreens := fyne.NewMenuItem("X screen", nil)
+	xScreens.ChildMenu = u.xScreenMenu()
+	dither := fyne.NewMenuItem("Dither ramps at low brightness", func() { u.setDither(!u.cfg.Dither) })
+	dither.Checked = u.cfg.Dither
+	reference := fyne.NewMenuItem("Display reference", nil)
+	reference.ChildMenu = u.referenceMenu()
+	fade := fyne.NewMenuItem("Preset fade", nil)
+	fade.ChildMenu = u.fadeMenu()
+	reapply := fyne.NewMenuItem("Reapply last values on launch", func() { u.setReapplyOnLaunch(!u.cfg.ReapplyOnLaunch) })
+	reapply.Checked = u.cfg.ReapplyOnLaunch
+	toolbar := fyne.NewMenuItem("Toolbar", nil)
+	toolbar.ChildMenu = u.toolbarMenu()
+	menu := fyne.NewMenu("",
+		fyne.NewMenuItem(fmt.Sprintf("Reading for %d min", u.cfg.ReadingMinutes), u.startReading),
+		fyne.NewMenuItem("Use current values for Reading", u.useForReading),
+		fyne.NewMenuItem("Use current values for Work", u.useForWork),
+		windDown,
+		presets,
+		quick,
+		pause,
+		fyne.NewMenuItemSeparator(),
+		auto,
+		fyne.NewMenuItem("Use current values for Auto day", u.useForAutoDay),
+		fyne.NewMenuItem("Use current values for Auto night", u.useForAutoNight),
+		fyne.NewMenuItem("Day and night times…", u.showAutoTimes),
+		daemon,
+		schedule,
+		fyne.NewMenuItem("Edit schedule…", func() { u.showScheduleEditor(false) }),
+		fyne.NewMenuItem("Edit weekend schedule…", func() { u.showScheduleEditor(true) }),
+		fyne.NewMenuItem("Preview today's schedule…", u.previewSchedule),
+		fyne.NewMenuItem("Cron rules…", u.showCronRules),
+		fyne.NewMenuItem("Import from redshift.conf…", u.offerRedshiftImport),
+		fyne.NewMenuItem("Export to redshift.conf…", u.exportRedshiftConf),
+		wake,
+		link,
+		monitors,
+		focus,
+		adaptive,
+		movie,
+		fyne.NewMenuItem("Use current values for Movie mode", u.useForMovie),
+		inhibit,
+		breaks,
+		breakDim,
+		pomodoro,
+		fyne.NewMenuItemSeparator(),
+		hosts,
+		places,
+		slots,
+		brightKeys,
+		triggers,
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Export gamma ramps…", u.exportRamps),
+		fyne.NewMenuItem("Import base correction…", u.importBase),
+		fyne.NewMenuItem("Clear base correction", u.clearBase),
+		dither,
+		reference,
+		fyne.NewMenuItemSeparator(),
+		history,
+		summary,
+		fyne.NewMenuItem("Export usage history (CSV)…", u.exportHistory),
+		fyne.NewMenuItemSeparator(),
+		toolbar,
+		reapply,
+		fyne.NewMenuItem("Slider steps…", u.showSteps),
+		fade,
+		fyne.NewMenuItem("Slider ranges…", u.showRanges),
+		fyne.NewMenuItem("White point (xy)…", u.showWhitePoint),
+		fyne.NewMenuItem("Benchmark apply latency…", u.showBenchmark),
+		backend,
+		ddc,
+		laptop,
+		keyboard,
+	)
+	if xScreens.ChildMenu != nil {
+		menu.Items = append(menu.Items, xScreens)
+	}
+	return menu
+}
 
-	w.ShowAndRun()
+// current snapshots the slider values. Must be called on the UI thread.
+func (u *uiState) current() Settings {
+	return Settings{
+		TempK:         int(u.tempK.Value()),
+		Brightness:    u.brightness.Value(),
+		Gamma:         u.gamma.Value(),
+		BlueReduction: u.blue.Value() / 100,
+		Tint:          math.Round(u.tint.Value()*1000) / 1000, // so a centred slider is exactly 0
+		WhiteX:        u.whiteX,
+		WhiteY:        u.whiteY,
+	}
+}
+
+// target is what should be on screen: the newest override if there is
+// one, otherwise the sliders plus any adaptive nudge.
+func (u *uiState) target() Settings {
+	if o, ok := u.activeOverride(); ok {
+		return o.settings
+	}
+	return u.nudge.apply(u.current())
 }
 
-func (u *uiState) scheduleApply(t int, b, g float64) {
+// setSliders moves the sliders to s without triggering an apply.
+func (u *uiState) setSliders(s Settings) {
+	u.silence = true
+	u.tempK.SetValue(float64(s.TempK))
+	u.brightness.SetValue(s.Brightness)
+	u.gamma.SetValue(s.Gamma)
+	u.blue.SetValue(s.BlueReduction * 100)
+	u.tint.SetValue(s.Tint)
+	u.silence = false
+	u.setWhite(s.WhiteX, s.WhiteY)
+}
+
+func (u *uiState) scheduleApply(s Settings) {
 	if u.cancel != nil {
 		u.cancel()
 		u.cancel = nil
@@ -129,84 +523,113 @@ func (u *uiState) scheduleApply(t int, b, g float64) {
 	if u.timer != nil {
 		u.timer.Stop()
 	}
+	u.rememberSliders()
+	s, warns := u.validate(s)
 	u.timer = time.AfterFunc(debounce, func() {
-		go u.apply(t, b, g)
+		go u.apply(s, warns)
 	})
 }
 
-func (u *uiState) apply(t int, b, g float64) {
-	args := []string{
-		"-m", "randr", // force X11 method; avoids Wayland probe
-		"-P",          // clear previous ramps so changes aren't compounded
-		"-O", fmt.Sprintf("%d", t),
-		"-g", fmt.Sprintf("%.2f:%.2f:%.2f", g, g, g),
-		"-b", fmt.Sprintf("%.2f", b),
-	}
-
+// apply puts s on screen. s must have passed validate; warns are what it
+// changed and are appended to the status message.
+func (u *uiState) apply(s Settings, warns []Warning) {
+	u.stopDither()
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	u.cancel = cancel
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "redshift", args...)
-	outBytes, err := cmd.CombinedOutput()
+	daemonMsg := ""
+	if u.daemon.running() {
+		if !u.daemonHeld.Load() {
+			return // redshift has the screen until something holds it off
+		}
+		u.yieldDaemon()
+		daemonMsg = " redshift's day and night are on hold and come back by themselves."
+	}
 
-	msg := strings.TrimSpace(string(outBytes))
+	s, ddcMsg := u.applyHardware(ctx, s)
+	b := u.backendFor(s)
+	s, overlayMsg := u.overlayFor(b, s)
+	err := b.Apply(ctx, s)
+	msg := "Applied."
+	if r, ok := b.(statusReporter); ok {
+		msg = r.Status()
+	}
+	if missing := b.Capabilities().lacks(u.needs(s)); len(missing) > 0 {
+		msg += fmt.Sprintf(" %s can't show: %s.", b.Name(), strings.Join(missing, ", "))
+	}
 	if ctx.Err() == context.DeadlineExceeded {
 		msg = "Timed out applying settings."
-	} else if err != nil && msg == "" {
-		msg = "redshift error: " + err.Error()
 	} else if err != nil {
-		msg = "redshift error: " + msg
-	} else if msg == "" {
-		msg = "Applied."
+		msg = b.Name() + " error: " + err.Error()
+	}
+	t := u.verifyTargetFor(b, s)
+	if err != nil {
+		t = nil
+	} else if t != nil && u.overridden(t) {
+		msg += " The gamma ramps don't show these settings; another program may have overridden them."
+		t = nil
 	}
+	u.verify.Store(t)
+	msg += ddcMsg + overlayMsg + daemonMsg + warningText(warns)
 	fyne.Do(func() { u.out.SetText(msg) })
 }
 
 func (u *uiState) reset() {
+	u.stopDither()
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	u.cancel = cancel
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "redshift", "-x")
-	outBytes, err := cmd.CombinedOutput()
-	msg := strings.TrimSpace(string(outBytes))
-	if err != nil && msg == "" {
+	u.verify.Store(nil)
+	if u.yieldDaemon() {
+		fyne.Do(u.holdDaemon)
+	}
+	_, ddcMsg := u.applyHardware(ctx, defaultSettings)
+	msg := "Reset to defaults."
+	u.overlay.dim(1)
+	if err := u.backendFor(defaultSettings).Reset(ctx); err != nil {
 		msg = "reset error: " + err.Error()
-	} else if err != nil {
-		msg = "reset error: " + msg
-	} else {
-		msg = "Reset to defaults."
 	}
-
+	msg += ddcMsg
 	fyne.Do(func() {
-		u.silence = true
-		u.tempK.SetValue(6500)
-		u.brightness.SetValue(1.00)
-		u.gamma.SetValue(1.00)
-		u.silence = false
+		u.stopFade()
+		u.setSliders(defaultSettings)
 		u.out.SetText(msg)
 	})
 }
 
+// showMenu pops menu up just below anchor.
+func (u *uiState) showMenu(anchor fyne.CanvasObject, menu *fyne.Menu) {
+	d := fyne.CurrentApp().Driver()
+	pos := d.AbsolutePositionForObject(anchor).Add(fyne.NewPos(0, anchor.Size().Height))
+	widget.ShowPopUpMenuAtPosition(menu, d.CanvasForObject(anchor), pos)
+}
+
 // ---------- helpers ----------
 
+// commandRunner runs name with args and returns its combined output.
+type commandRunner func(ctx context.Context, name string, args ...string) ([]byte, error)
+
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, name, args...).CombinedOutput()
+}
+
 // thinDivider returns a full-width thin horizontal line with the given color.
 func thinDivider(c color.Color) *fyne.Container {
-    line := canvas.NewRectangle(c)
-    line.SetMinSize(fyne.NewSize(0, 2)) // 1px high line
+	line := canvas.NewRectangle(c)
+	line.SetMinSize(fyne.NewSize(0, 2)) // 1px high line
 
-    const pad float32 = 0 // if want to add horizontal padding
-    left := canvas.NewRectangle(color.Transparent)
-    left.SetMinSize(fyne.NewSize(pad, 1))
-    right := canvas.NewRectangle(color.Transparent)
-    right.SetMinSize(fyne.NewSize(pad, 1))
+	const pad float32 = 0 // if want to add horizontal padding
+	left := canvas.NewRectangle(color.Transparent)
+	left.SetMinSize(fyne.NewSize(pad, 1))
+	right := canvas.NewRectangle(color.Transparent)
+	right.SetMinSize(fyne.NewSize(pad, 1))
 
-    // Center expands to the remaining width between left/right
-    return container.NewBorder(nil, nil, left, right, line)
+	// Center expands to the remaining width between left/right
+	return container.NewBorder(nil, nil, left, right, line)
 }
 
-
 // newRoundRect builds a rounded rectangle canvas object with fill, stroke and radius.
 // It expands automatically inside a container.NewMax(...).
 func newRoundRect(fill, stroke color.Color, strokeWidth float32, radius float32) *canvas.Rectangle {
@@ -218,16 +641,16 @@ func newRoundRect(fill, stroke color.Color, strokeWidth float32, radius float32)
 }
 
 func inset(obj fyne.CanvasObject, top, right, bottom, left float32) *fyne.Container {
-    spacer := func(w, h float32) fyne.CanvasObject {
-        r := canvas.NewRectangle(color.Transparent)
-        r.SetMinSize(fyne.NewSize(w, h))
-        return r
-    }
-    return container.NewBorder(
-        spacer(0, top),    // top
-        spacer(0, bottom), // bottom
-        spacer(left, 0),   // left
-        spacer(right, 0),  // right
-        obj,
-    )
-}
\ No newline at end of file
+	spacer := func(w, h float32) fyne.CanvasObject {
+		r := canvas.NewRectangle(color.Transparent)
+		r.SetMinSize(fyne.NewSize(w, h))
+		return r
+	}
+	return container.NewBorder(
+		spacer(0, top),    // top
+		spacer(0, bottom), // bottom
+		spacer(left, 0),   // left
+		spacer(right, 0),  // right
+		obj,
+	)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"path"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"github.com/godbus/dbus/v5"
+	"github.com/jezek/xgb"
+)
+
+const (
+	moviePollInterval = 3 * time.Second
+	movieOverride     = "Movie"
+	mprisPrefix       = "org.mpris.MediaPlayer2."
+)
+
+var (
+	videoPlayers = []string{"mpv", "vlc", "totem", "celluloid", "smplayer", "kodi", "haruna", "dragonplayer", "mplayer"}
+	browsers     = []string{"firefox", "chromium", "chrome", "brave", "vivaldi", "opera", "epiphany", "librewolf"}
+	videoExts    = []string{".mkv", ".mp4", ".webm", ".avi", ".mov", ".m4v", ".wmv", ".flv", ".ts", ".mpg", ".mpeg"}
+)
+
+// setMovieMode starts or stops watching for video playback and persists
+// the choice. While a video plays the Movie preset is pushed as an override.
+func (u *uiState) setMovieMode(on bool) {
+	if on == (u.movieStop != nil) {
+		return
+	}
+	u.cfg.MovieMode = on
+	u.saveConfig()
+
+	if !on {
+		close(u.movieStop)
+		u.movieStop = nil
+		u.popOverride(movieOverride)
+		return
+	}
+	stop := make(chan struct{})
+	u.movieStop = stop
+	go u.movieLoop(stop)
+}
+
+// useForMovie stores the current sliders as the Movie preset.
+func (u *uiState) useForMovie() {
+	u.cfg.MoviePreset = u.current()
+	u.saveConfig()
+	u.syncProfiles()
+	u.out.SetText("Movie preset updated.")
+}
+
+func (u *uiState) movieLoop(stop chan struct{}) {
+	tick := time.NewTicker(moviePollInterval)
+	defer tick.Stop()
+	playing := false
+	for {
+		now := videoPlaying()
+		if now != playing {
+			playing = now
+			fyne.Do(func() {
+				if u.movieStop != stop {
+					return
+				}
+				if now {
+					u.pushOverride(movieOverride, u.cfg.MoviePreset)
+				} else {
+					u.popOverride(movieOverride)
+				}
+			})
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-tick.C:
+		}
+	}
+}
+
+// videoPlaying reports whether an MPRIS player is playing a video or a
+// video player or browser is fullscreen.
+func videoPlaying() bool {
+	return mprisVideoPlaying() || fullscreenVideo()
+}
+
+func mprisVideoPlaying() bool {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return false
+	}
+	var names []string
+	if err := conn.BusObject().Call("org.freedesktop.DBus.ListNames", 0).Store(&names); err != nil {
+		return false
+	}
+	for _, name := range names {
+		if !strings.HasPrefix(name, mprisPrefix) {
+			continue
+		}
+		obj := conn.Object(name, "/org/mpris/MediaPlayer2")
+		status, err := obj.GetProperty("org.mpris.MediaPlayer2.Player.PlaybackStatus")
+		if err != nil || status.Value() != "Playing" {
+			continue
+		}
+		if containsAny(strings.ToLower(strings.TrimPrefix(name, mprisPrefix)), videoPlayers) {
+			return true
+		}
+		meta, err := obj.GetProperty("org.mpris.MediaPlayer2.Player.Metadata")
+		if err != nil {
+			continue
+		}
+		m, _ := meta.Value().(map[string]dbus.Variant)
+		if url, ok := m["xesam:url"].Value().(string); ok && isVideoURL(url) {
+			return true
+		}
+	}
+	return false
+}
+
+func fullscreenVideo() bool {
+	X, err := xgb.NewConn()
+	if err != nil {
+		return false
+	}
+	defer X.Close()
+
+	win, err := activeWindow(X)
+	if err != nil || !isFullscreen(X, win) {
+		return false
+	}
+	class := wmClass(X, win)
+	return containsAny(class, videoPlayers) || containsAny(class, browsers)
+}
+
+func isVideoURL(url string) bool {
+	ext := strings.ToLower(path.Ext(url))
+	for _, e := range videoExts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(s string, subs []string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
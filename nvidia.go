@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+const backendNvidia = "nvidia-settings"
+
+// nvidiaDetected reports whether the proprietary NVIDIA driver is loaded
+// and nvidia-settings is installed.
+func nvidiaDetected() bool {
+	if _, err := os.Stat("/proc/driver/nvidia/version"); err != nil {
+		return false
+	}
+	return hasCommand("nvidia-settings")
+}
+
+// nvidiaArgs maps s onto the NV-CONTROL colour correction attributes.
+// They are applied to a centred ramp as (x-0.5)*(1+contrast)+0.5+brightness,
+// so scaling a channel by k means a contrast of k-1 and a brightness of
+// (k-1)/2; the white point and dimming are both such scales. Blue
+// reduction goes into the blue gamma, as for every other backend.
+func nvidiaArgs(s Settings) []string {
+	wr, wg, wb := s.white()
+	gr, gg, gb := s.channelGamma()
+	var args []string
+	for _, ch := range []struct {
+		name         string
+		scale, gamma float64
+	}{{"Red", wr * s.Brightness, gr}, {"Green", wg * s.Brightness, gg}, {"Blue", wb * s.Brightness, gb}} {
+		k := ch.scale - 1
+		args = append(args,
+			"-a", fmt.Sprintf("%sContrast=%.3f", ch.name, k),
+			"-a", fmt.Sprintf("%sBrightness=%.3f", ch.name, k/2),
+			"-a", fmt.Sprintf("%sGamma=%.3f", ch.name, ch.gamma))
+	}
+	return args
+}
+
+// nvidiaBackend sets the colour correction through nvidia-settings, for
+// drivers whose RandR gamma is unreliable.
+type nvidiaBackend struct {
+	u *uiState
+}
+
+func (b *nvidiaBackend) Name() string { return backendNvidia }
+
+func (b *nvidiaBackend) Available() bool { return nvidiaDetected() }
+
+func (b *nvidiaBackend) Capabilities() Capabilities {
+	return Capabilities{Temperature: true, Brightness: true, Gamma: true, ChannelGamma: true, WhitePoint: true, Remote: true}
+}
+
+func (b *nvidiaBackend) Apply(ctx context.Context, s Settings) error {
+	if out, err := b.u.exec(ctx, "nvidia-settings", nvidiaArgs(s)...); err != nil {
+		return commandError(out, err)
+	}
+	return nil
+}
+
+func (b *nvidiaBackend) Reset(ctx context.Context) error {
+	return b.Apply(ctx, defaultSettings)
+}
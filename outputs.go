@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// OutputAdjust is how one monitor differs from the sliders, which set all
+// of them. Being relative, it follows the schedule and everything else
+// that moves the sliders. The zero value changes nothing.
+type OutputAdjust struct {
+	TempK      int     `json:"temp_k,omitempty"`     // Kelvin added
+	Brightness float64 `json:"brightness,omitempty"` // added
+	Gamma      float64 `json:"gamma,omitempty"`      // added
+
+	// Per-channel gamma added on top, for matching the tint of
+	// mismatched panels.
+	GammaR float64 `json:"gamma_r,omitempty"`
+	GammaG float64 `json:"gamma_g,omitempty"`
+	GammaB float64 `json:"gamma_b,omitempty"`
+}
+
+func (a OutputAdjust) apply(s Settings) Settings {
+	s = s.shifted(a.TempK, a.Brightness)
+	s.Gamma += a.Gamma
+	s.gammaRGB = [3]float64{a.GammaR, a.GammaG, a.GammaB}
+	return s
+}
+
+// key is what the output's Config.Outputs entry is stored under: the
+// monitor's EDID identity, so its settings follow it to any connector,
+// or the output name for a monitor without an EDID.
+func (o seatOutput) key() string {
+	if e, ok := parseEDID(o.edid); ok {
+		return e.key()
+	}
+	return o.name
+}
+
+// label names the output for the user, by model when the EDID has one.
+func (o seatOutput) label() string {
+	if e, ok := parseEDID(o.edid); ok && e.name != "" {
+		return e.name + " (" + o.name + ")"
+	}
+	return o.name
+}
+
+// adjust returns the output's entry in m. Entries from before monitors
+// were told apart by EDID are still found by output name.
+func (o seatOutput) adjust(m map[string]OutputAdjust) (OutputAdjust, bool) {
+	if a, ok := m[o.key()]; ok {
+		return a, true
+	}
+	a, ok := m[o.name]
+	return a, ok
+}
+
+// crtcSettings maps the settings for all monitors to what one CRTC shows.
+type crtcSettings func(crtc int, s Settings) Settings
+
+// perOutput reports whether any CRTC may show something other than the
+// sliders. Safe to call from any goroutine.
+func (u *uiState) perOutput() bool {
+	return u.focused.Load() != 0 || len(*u.outputs.Load()) > 0
+}
+
+// outputSettings returns what each CRTC shows: its monitor's adjustment
+// on top of the sliders, then focus emphasis. It looks the monitors up
+// once, so call it once per apply. Safe to call from any goroutine.
+func (u *uiState) outputSettings() crtcSettings {
+	adjusts := map[int]OutputAdjust{}
+	if m := *u.outputs.Load(); len(m) > 0 && u.remote.Load() == nil {
+		outs, _ := seatOutputs(u.seat.foreignEDIDs())
+		for _, o := range outs {
+			if a, ok := o.adjust(m); ok {
+				adjusts[o.crtc] = a
+			}
+		}
+	}
+	focused := u.focusedCRTC()
+	r := *u.limits.Load()
+	return func(crtc int, s Settings) Settings {
+		if crtc < 0 {
+			return s // on another X screen
+		}
+		if a, ok := adjusts[crtc]; ok {
+			s = r.clamp(a.apply(s))
+		}
+		if focused >= 0 && crtc != focused {
+			s = u.unfocused(s)
+		}
+		return s
+	}
+}
+
+// setOutputAdjust stores a for o and reapplies.
+func (u *uiState) setOutputAdjust(o seatOutput, a OutputAdjust) {
+	m := maps.Clone(u.cfg.Outputs)
+	if m == nil {
+		m = map[string]OutputAdjust{}
+	}
+	delete(m, o.name)
+	if a == (OutputAdjust{}) {
+		delete(m, o.key())
+	} else {
+		m[o.key()] = a
+	}
+	u.cfg.Outputs = m
+	u.outputs.Store(&m)
+	u.saveOutputs()
+	u.scheduleApply(u.target())
+}
+
+// saveOutputs writes the config once the Monitors sliders have rested for
+// rememberDelay, so a drag across linked monitors is saved once.
+func (u *uiState) saveOutputs() {
+	if u.outputsTimer != nil {
+		u.outputsTimer.Stop()
+	}
+	u.outputsTimer = time.AfterFunc(rememberDelay, func() { fyne.Do(u.saveConfig) })
+}
+
+// showMonitors opens a tab per connected monitor with sliders that shift
+// it away from the main ones. The shifts belong to the monitor, not the
+// connector, and come back whenever it is plugged in.
+func (u *uiState) showMonitors() {
+	if u.remote.Load() != nil {
+		u.out.SetText("Per-monitor settings only reach this machine's displays.")
+		return
+	}
+	outs, err := seatOutputs(u.seat.foreignEDIDs())
+	if err != nil || len(outs) == 0 {
+		u.out.SetText("No monitors found over RandR; per-monitor settings need X11.")
+		return
+	}
+	type monitorTab struct {
+		o                   seatOutput
+		temp, bright, gamma *LabeledSlider
+		red, green, blue    *LabeledSlider
+	}
+	store := func(t *monitorTab) {
+		u.setOutputAdjust(t.o, OutputAdjust{
+			TempK:      int(t.temp.Value()),
+			Brightness: t.bright.Value(),
+			Gamma:      t.gamma.Value(),
+			GammaR:     t.red.Value(),
+			GammaG:     t.green.Value(),
+			GammaB:     t.blue.Value(),
+		})
+	}
+	var all []*monitorTab
+	syncing := false // sliders are being moved by the link or a reset, not by hand
+	tabs := container.NewAppTabs()
+	for i, o := range outs {
+		a, _ := o.adjust(u.cfg.Outputs)
+		t := &monitorTab{o: o,
+			temp:   NewLabeledSlider("Temperature shift", -3000, 3000, 100, float64(a.TempK), "%+.0f", "K"),
+			bright: NewLabeledSlider("Brightness shift", -0.50, 0.50, 0.01, a.Brightness, "%+.2f", ""),
+			gamma:  NewLabeledSlider("Gamma shift", -0.50, 0.50, 0.01, a.Gamma, "%+.2f", ""),
+			red:    NewLabeledSlider("Red gamma", -0.50, 0.50, 0.01, a.GammaR, "%+.2f", ""),
+			green:  NewLabeledSlider("Green gamma", -0.50, 0.50, 0.01, a.GammaG, "%+.2f", ""),
+			blue:   NewLabeledSlider("Blue gamma", -0.50, 0.50, 0.01, a.GammaB, "%+.2f", ""),
+		}
+		all = append(all, t)
+		// Linked, a drag moves the same slider on every other monitor by
+		// as much, so the differences between them stay.
+		follow := func(pick func(*monitorTab) *LabeledSlider) func(float64) {
+			last := pick(t).Value()
+			return func(v float64) {
+				delta := v - last
+				last = v
+				if syncing {
+					return
+				}
+				if u.cfg.LinkOutputs {
+					syncing = true
+					for _, other := range all {
+						if other != t {
+							s := pick(other)
+							s.SetValue(s.Value() + delta)
+							store(other)
+						}
+					}
+					syncing = false
+				}
+				store(t)
+			}
+		}
+		t.temp.SetOnChanged(follow(func(t *monitorTab) *LabeledSlider { return t.temp }))
+		t.bright.SetOnChanged(follow(func(t *monitorTab) *LabeledSlider { return t.bright }))
+		t.gamma.SetOnChanged(follow(func(t *monitorTab) *LabeledSlider { return t.gamma }))
+		t.red.SetOnChanged(follow(func(t *monitorTab) *LabeledSlider { return t.red }))
+		t.green.SetOnChanged(follow(func(t *monitorTab) *LabeledSlider { return t.green }))
+		t.blue.SetOnChanged(follow(func(t *monitorTab) *LabeledSlider { return t.blue }))
+		same := widget.NewButton("Same as the main sliders", func() {
+			syncing = true
+			t.temp.SetValue(0)
+			t.bright.SetValue(0)
+			t.gamma.SetValue(0)
+			t.red.SetValue(0)
+			t.green.SetValue(0)
+			t.blue.SetValue(0)
+			syncing = false
+			store(t)
+		})
+		tabs.Append(container.NewTabItem(fmt.Sprintf("%d: %s", i+1, o.label()), container.NewVBox(
+			t.temp.View(), t.bright.View(), t.gamma.View(),
+			widget.NewSeparator(),
+			t.red.View(), t.green.View(), t.blue.View(),
+			same)))
+	}
+	link := widget.NewCheck("Link displays", func(on bool) {
+		u.cfg.LinkOutputs = on
+		u.saveConfig()
+	})
+	link.Checked = u.cfg.LinkOutputs
+	identify := widget.NewButton("Identify", func() { u.identifyMonitors(outs) })
+	top := container.NewBorder(nil, nil, nil, identify, link)
+	d := dialog.NewCustom("Monitors", "Close", container.NewBorder(top, nil, nil, nil, tabs), u.win)
+	d.Resize(fyne.NewSize(380, 0))
+	d.Show()
+	u.out.SetText(fmt.Sprintf("%d monitors found.", len(outs)))
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/shape"
+	"github.com/jezek/xgb/xproto"
+)
+
+const backendOverlay = "overlay"
+
+// overlayBackend dims by covering the screen with a translucent black
+// window that clicks pass through, for when nothing can touch the gamma:
+// remote desktops, or compositors without a gamma protocol but with
+// XWayland. It needs a compositing manager; without one the window would
+// be opaque. The apply pipeline also uses it for the brightness of
+// backends that can't dim.
+type overlayBackend struct {
+	u      *uiState
+	mu     sync.Mutex
+	X      *xgb.Conn // open while the overlay is shown; closing it destroys the window
+	win    xproto.Window
+	probe  sync.Once
+	usable bool
+}
+
+func (b *overlayBackend) Name() string { return backendOverlay }
+
+func (b *overlayBackend) Capabilities() Capabilities { return Capabilities{Brightness: true} }
+
+// Available probes the X server once for a compositing manager and a
+// visual with alpha.
+func (b *overlayBackend) Available() bool {
+	b.probe.Do(func() {
+		if os.Getenv("DISPLAY") == "" {
+			return
+		}
+		X, err := xgb.NewConn()
+		if err != nil {
+			return
+		}
+		defer X.Close()
+		_, ok := argbVisual(xproto.Setup(X).DefaultScreen(X))
+		b.usable = ok && composited(X)
+	})
+	return b.usable
+}
+
+func (b *overlayBackend) Apply(_ context.Context, s Settings) error {
+	return b.dim(s.Brightness)
+}
+
+func (b *overlayBackend) Reset(context.Context) error {
+	return b.dim(1)
+}
+
+// dim shows the overlay at brightness, or removes it at full brightness.
+func (b *overlayBackend) dim(brightness float64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if brightness >= 1 {
+		if b.X != nil {
+			b.X.Close()
+			b.X = nil
+		}
+		return nil
+	}
+	if b.u.remote.Load() != nil {
+		return errors.New("the overlay only covers this machine's screen")
+	}
+	if b.X == nil {
+		if err := b.open(); err != nil {
+			return err
+		}
+	}
+	alpha := uint32(math.Round((1 - brightness) * 0xff))
+	xproto.ChangeWindowAttributes(b.X, b.win, xproto.CwBackPixel, []uint32{alpha << 24}) // premultiplied ARGB black
+	xproto.ClearArea(b.X, false, b.win, 0, 0, 0, 0)
+	// Windows mapped since, menus mostly, would otherwise end up on top.
+	return xproto.ConfigureWindowChecked(b.X, b.win, xproto.ConfigWindowStackMode, []uint32{xproto.StackModeAbove}).Check()
+}
+
+// open maps a full-screen, override-redirect window with an empty input
+// shape, so every click lands on whatever is underneath.
+func (b *overlayBackend) open() error {
+	X, err := xgb.NewConn()
+	if err != nil {
+		return err
+	}
+	fail := func(err error) error {
+		X.Close()
+		return err
+	}
+	if err := shape.Init(X); err != nil {
+		return fail(err)
+	}
+	screen := xproto.Setup(X).DefaultScreen(X)
+	visual, ok := argbVisual(screen)
+	if !ok {
+		return fail(errors.New("the X server has no visual with alpha"))
+	}
+	cmap, err := xproto.NewColormapId(X)
+	if err != nil {
+		return fail(err)
+	}
+	if err := xproto.CreateColormapChecked(X, xproto.ColormapAllocNone, cmap, screen.Root, visual).Check(); err != nil {
+		return fail(err)
+	}
+	win, err := xproto.NewWindowId(X)
+	if err != nil {
+		return fail(err)
+	}
+	err = xproto.CreateWindowChecked(X, 32, win, screen.Root, 0, 0, screen.WidthInPixels, screen.HeightInPixels, 0,
+		xproto.WindowClassInputOutput, visual,
+		xproto.CwBackPixel|xproto.CwBorderPixel|xproto.CwOverrideRedirect|xproto.CwColormap,
+		[]uint32{0, 0, 1, uint32(cmap)}).Check()
+	if err != nil {
+		return fail(err)
+	}
+	if err := shape.RectanglesChecked(X, shape.SoSet, shape.SkInput, xproto.ClipOrderingUnsorted, win, 0, 0, nil).Check(); err != nil {
+		return fail(err)
+	}
+	if err := xproto.MapWindowChecked(X, win).Check(); err != nil {
+		return fail(err)
+	}
+	b.X, b.win = X, win
+	return nil
+}
+
+// argbVisual returns a 32-bit TrueColor visual, which has an alpha
+// channel under a compositing manager.
+func argbVisual(screen *xproto.ScreenInfo) (xproto.Visualid, bool) {
+	for _, d := range screen.AllowedDepths {
+		if d.Depth != 32 {
+			continue
+		}
+		for _, v := range d.Visuals {
+			if v.Class == xproto.VisualClassTrueColor {
+				return v.VisualId, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// composited reports whether a compositing manager owns the default
+// screen's _NET_WM_CM_Sn selection.
+func composited(X *xgb.Conn) bool {
+	atom, err := internAtom(X, "_NET_WM_CM_S"+strconv.Itoa(X.DefaultScreen))
+	if err != nil {
+		return false
+	}
+	r, err := xproto.GetSelectionOwner(X, atom).Reply()
+	return err == nil && r.Owner != 0
+}
+
+// overlayFor hands s.Brightness to the overlay when b can't dim and our
+// ramps aren't there to take over, and returns s with what is left for
+// b. Otherwise it lifts any overlay. Safe to call from any goroutine.
+func (u *uiState) overlayFor(b Backend, s Settings) (Settings, string) {
+	if b == Backend(u.overlay) {
+		return s, ""
+	}
+	if b.Capabilities().Brightness || s.Brightness >= 1 || u.remote.Load() != nil || !available(u.overlay) {
+		u.overlay.dim(1)
+		return s, ""
+	}
+	if err := u.overlay.dim(s.Brightness); err != nil {
+		return s, " Overlay error: " + err.Error() + "."
+	}
+	s.Brightness = 1
+	return s, ""
+}
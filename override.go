@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+// override temporarily replaces the slider values on screen, e.g. while a
+// video plays. Overrides stack and the newest one wins; popping it brings
+// back whatever was underneath.
+type override struct {
+	name     string
+	settings Settings
+	until    time.Time // zero when the override only ends explicitly
+	timer    *time.Timer
+	started  time.Time
+}
+
+// pushOverride activates s under name, replacing an override of the same
+// name if there is one.
+func (u *uiState) pushOverride(name string, s Settings) {
+	u.pushTimedOverride(name, s, 0)
+}
+
+// pushTimedOverride is pushOverride with an expiry; d <= 0 means none.
+func (u *uiState) pushTimedOverride(name string, s Settings, d time.Duration) {
+	u.removeOverride(name)
+	o := override{name: name, settings: s, started: time.Now()}
+	if d > 0 {
+		until := time.Now().Add(d)
+		o.until = until
+		o.timer = time.AfterFunc(d, func() {
+			fyne.Do(func() { u.expireOverride(name, until) })
+		})
+	}
+	u.overrides = append(u.overrides, o)
+	u.recordOverride(name, s)
+	u.overridesChanged()
+}
+
+// updateOverride changes the settings of the active override called name
+// in place, for ramps that move a little at a time. It reports whether
+// there was one; unlike a push it isn't recorded in the history.
+func (u *uiState) updateOverride(name string, s Settings) bool {
+	for i := range u.overrides {
+		if u.overrides[i].name == name {
+			u.overrides[i].settings = s
+			u.scheduleApply(u.target())
+			return true
+		}
+	}
+	return false
+}
+
+// popOverride ends the override called name; it is a no-op if none is active.
+func (u *uiState) popOverride(name string) {
+	if u.removeOverride(name) {
+		u.overridesChanged()
+	}
+}
+
+// clearOverrides drops every override, used when the user takes over by
+// moving a slider.
+func (u *uiState) clearOverrides() {
+	if len(u.overrides) == 0 {
+		return
+	}
+	for _, o := range u.overrides {
+		if o.timer != nil {
+			o.timer.Stop()
+		}
+		u.recordOverrideEnd(o)
+	}
+	u.overrides = nil
+	u.overridesChanged()
+}
+
+func (u *uiState) activeOverride() (override, bool) {
+	if len(u.overrides) == 0 {
+		return override{}, false
+	}
+	return u.overrides[len(u.overrides)-1], true
+}
+
+func (u *uiState) hasOverride(name string) bool {
+	for _, o := range u.overrides {
+		if o.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// expireOverride pops name only if it is still the instance that was set
+// to end at until, so a late timer can't end a newer push of the same name.
+func (u *uiState) expireOverride(name string, until time.Time) {
+	for _, o := range u.overrides {
+		if o.name == name && o.until.Equal(until) {
+			u.popOverride(name)
+			return
+		}
+	}
+}
+
+func (u *uiState) removeOverride(name string) bool {
+	for i, o := range u.overrides {
+		if o.name == name {
+			if o.timer != nil {
+				o.timer.Stop()
+			}
+			u.recordOverrideEnd(o)
+			u.overrides = append(u.overrides[:i], u.overrides[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (u *uiState) overridesChanged() {
+	u.refreshMode()
+	u.syncGaming()
+	u.syncTray()
+	u.syncDaemonHold()
+	u.scheduleApply(u.target())
+}
+
+// refreshMode renders the active overrides into the status bar, with a
+// countdown for timed ones, then the next scheduled change. A one-second
+// ticker runs while anything counts down.
+func (u *uiState) refreshMode() {
+	names := make([]string, len(u.overrides))
+	timed := false
+	for i, o := range u.overrides {
+		names[i] = o.name
+		if !o.until.IsZero() {
+			timed = true
+			left := max(time.Until(o.until).Round(time.Second), 0)
+			names[i] += fmt.Sprintf(" %d:%02d", int(left.Minutes()), int(left.Seconds())%60)
+		}
+	}
+	if next := u.upcoming.String(); next != "" {
+		timed = true
+		names = append(names, next)
+	}
+	u.mode.SetText(strings.Join(names, " · "))
+
+	switch {
+	case timed && u.modeStop == nil:
+		stop := make(chan struct{})
+		u.modeStop = stop
+		go func() {
+			tick := time.NewTicker(time.Second)
+			defer tick.Stop()
+			for {
+				select {
+				case <-stop:
+					return
+				case <-tick.C:
+					fyne.Do(u.refreshMode)
+				}
+			}
+		}()
+	case !timed && u.modeStop != nil:
+		close(u.modeStop)
+		u.modeStop = nil
+	}
+}
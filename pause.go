@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+const pauseOverride = "Paused"
+
+// pauseChoices are the Pause menu's durations, in minutes.
+var pauseChoices = []int{15, 30, 60}
+
+// pause shows neutral colour for minutes, e.g. for photo editing, after
+// which the sliders' settings come back on their own. Pausing again
+// restarts the countdown.
+func (u *uiState) pause(minutes int) {
+	d := time.Duration(minutes) * time.Minute
+	u.pushTimedOverride(pauseOverride, defaultSettings, d)
+	u.out.SetText(fmt.Sprintf("Paused until %s.", time.Now().Add(d).Format("15:04")))
+}
+
+// pauseUntilMorning pauses until the next sunrise, or until Auto's day
+// time where that is set by the clock or there is no location.
+func (u *uiState) pauseUntilMorning() {
+	now := time.Now()
+	var loc *Location
+	at := TimeSpec("sunrise")
+	if l, ok := u.location(); ok && !u.cfg.AutoClock {
+		loc = &l
+	} else {
+		at = u.cfg.AutoDayAt
+	}
+	for _, dd := range []int{0, 1, 2} { // the sun may not rise tomorrow this far north
+		if t, err := at.resolve(now.AddDate(0, 0, dd), loc); err == nil && t.After(now) {
+			u.pushTimedOverride(pauseOverride, defaultSettings, t.Sub(now))
+			u.out.SetText("Paused until " + t.Format("Mon 15:04") + ".")
+			return
+		}
+	}
+	u.out.SetText("No sunrise in the next days here; pause for a set time instead.")
+}
+
+func (u *uiState) resume() {
+	u.popOverride(pauseOverride)
+	u.out.SetText("Resumed.")
+}
+
+// pauseMenu offers the pause durations, and resuming early while paused.
+func (u *uiState) pauseMenu() *fyne.Menu {
+	var items []*fyne.MenuItem
+	for _, n := range pauseChoices {
+		items = append(items, fyne.NewMenuItem(fmt.Sprintf("For %d minutes", n), func() { u.pause(n) }))
+	}
+	items = append(items, fyne.NewMenuItem("Until sunrise", u.pauseUntilMorning))
+	if u.hasOverride(pauseOverride) {
+		items = append(items, fyne.NewMenuItemSeparator(), fyne.NewMenuItem("Resume now", u.resume))
+	}
+	return fyne.NewMenu("Pause", items...)
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/godbus/dbus/v5"
+)
+
+const ssidPollInterval = 30 * time.Second
+
+// Place is a named location such as "Home" or "Office". Joining one of its
+// Wi-Fi networks makes it the active place. A place may keep a schedule
+// of its own; without one the general schedule is followed there.
+type Place struct {
+	Name     string   `json:"name"`
+	Location Location `json:"location"`
+	SSIDs    []string `json:"ssids,omitempty"`
+
+	ScheduleEntries []ScheduleEntry `json:"schedule_entries,omitempty"`
+	WeekendEntries  []ScheduleEntry `json:"weekend_schedule_entries,omitempty"`
+}
+
+// activePlace returns the active place in Config.Places, or nil.
+func (u *uiState) activePlace() *Place {
+	for i := range u.cfg.Places {
+		if u.cfg.Places[i].Name == u.cfg.ActivePlace {
+			return &u.cfg.Places[i]
+		}
+	}
+	return nil
+}
+
+// scheduleEntries returns the weekday and weekend entries in effect, for
+// editing in place: the active place's if it has its own schedule,
+// otherwise the general ones. where names the place, or is "".
+func (u *uiState) scheduleEntries() (weekdays, weekend *[]ScheduleEntry, where string) {
+	if p := u.activePlace(); p != nil && len(p.ScheduleEntries) > 0 {
+		return &p.ScheduleEntries, &p.WeekendEntries, p.Name
+	}
+	return &u.cfg.ScheduleEntries, &u.cfg.WeekendEntries, ""
+}
+
+// week returns the schedule in effect, see scheduleEntries.
+func (u *uiState) week() weekSchedule {
+	weekdays, weekend, _ := u.scheduleEntries()
+	return weekSchedule{slices.Clone(*weekdays), slices.Clone(*weekend)}
+}
+
+// setPlaceSchedule gives the active place a schedule of its own, copied
+// from the general one to start with, or has it follow the general one
+// again.
+func (u *uiState) setPlaceSchedule(own bool) {
+	p := u.activePlace()
+	if p == nil {
+		return
+	}
+	if own {
+		p.ScheduleEntries = slices.Clone(u.cfg.ScheduleEntries)
+		p.WeekendEntries = slices.Clone(u.cfg.WeekendEntries)
+		u.out.SetText(p.Name + " has its own schedule now; edit it from the menu.")
+	} else {
+		p.ScheduleEntries, p.WeekendEntries = nil, nil
+		u.out.SetText(p.Name + " follows the general schedule.")
+	}
+	u.saveConfig()
+	if u.scheduleStop != nil {
+		u.restartSchedule()
+	}
+}
+
+// location returns where we are: the active place if one is set,
+// otherwise the single configured location.
+func (u *uiState) location() (Location, bool) {
+	for _, p := range u.cfg.Places {
+		if p.Name == u.cfg.ActivePlace {
+			return p.Location, true
+		}
+	}
+	if u.cfg.Location != nil {
+		return *u.cfg.Location, true
+	}
+	return Location{}, false
+}
+
+func (u *uiState) setActivePlace(name string) {
+	if u.cfg.ActivePlace == name {
+		return
+	}
+	u.cfg.ActivePlace = name
+	u.saveConfig()
+	u.out.SetText("Now at " + name + ".")
+	u.locationChanged()
+}
+
+// placesMenu lists the places with the active one checked.
+func (u *uiState) placesMenu() *fyne.Menu {
+	var items []*fyne.MenuItem
+	for _, p := range u.cfg.Places {
+		name := p.Name
+		item := fyne.NewMenuItem(name, func() { u.setActivePlace(name) })
+		item.Checked = name == u.cfg.ActivePlace
+		items = append(items, item)
+	}
+	if len(items) > 0 {
+		items = append(items, fyne.NewMenuItemSeparator())
+	}
+	items = append(items, fyne.NewMenuItem("Add place…", u.showAddPlace), fyne.NewMenuItem("Location…", u.showLocation))
+	geoclue := fyne.NewMenuItem("Find location with GeoClue", func() { u.setGeoClue(!u.cfg.GeoClue) })
+	geoclue.Checked = u.cfg.GeoClue
+	ip := fyne.NewMenuItem("Estimate location from IP address (online)", func() { u.setIPLocation(!u.cfg.IPLocation) })
+	ip.Checked = u.cfg.IPLocation
+	items = append(items, geoclue, ip)
+	if p := u.activePlace(); p != nil {
+		own := len(p.ScheduleEntries) > 0
+		schedule := fyne.NewMenuItem("Own schedule at "+p.Name, func() { u.setPlaceSchedule(!own) })
+		schedule.Checked = own
+		items = append(items, schedule, fyne.NewMenuItem("Remove "+p.Name, u.removeActivePlace))
+	}
+	return fyne.NewMenu("Places", items...)
+}
+
+func (u *uiState) showAddPlace() {
+	name := widget.NewEntry()
+	lat := widget.NewEntry()
+	lat.SetPlaceHolder("e.g. " + formatNum("%.1f", 52.5))
+	lon := widget.NewEntry()
+	lon.SetPlaceHolder("e.g. " + formatNum("%.1f", 13.4))
+	ssids := widget.NewEntry()
+	ssids.SetPlaceHolder("comma separated, optional")
+
+	dialog.ShowForm("Add place", "Add", "Cancel", []*widget.FormItem{
+		widget.NewFormItem("Name", name),
+		widget.NewFormItem("Latitude", lat),
+		widget.NewFormItem("Longitude", lon),
+		widget.NewFormItem("Wi-Fi networks", ssids),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+		p, err := parsePlace(name.Text, lat.Text, lon.Text, ssids.Text)
+		if err != nil {
+			u.out.SetText("Place not added: " + err.Error())
+			return
+		}
+		p.Location = p.Location.coarse(u.cfg.LocationPrecision)
+		u.cfg.Places = slices.DeleteFunc(u.cfg.Places, func(q Place) bool { return q.Name == p.Name })
+		u.cfg.Places = append(u.cfg.Places, p)
+		u.cfg.ActivePlace = p.Name
+		u.saveConfig()
+		u.out.SetText(fmt.Sprintf("Added %s (%s).", p.Name, p.Location))
+		u.syncSSIDWatch()
+		u.locationChanged()
+	}, u.win)
+}
+
+func parsePlace(name, lat, lon, ssids string) (Place, error) {
+	p := Place{Name: strings.TrimSpace(name)}
+	if p.Name == "" {
+		return p, errors.New("name is empty")
+	}
+	var err error
+	if p.Location, err = parseLocation(lat, lon); err != nil {
+		return p, err
+	}
+	for _, s := range strings.Split(ssids, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			p.SSIDs = append(p.SSIDs, s)
+		}
+	}
+	return p, nil
+}
+
+func (u *uiState) removeActivePlace() {
+	name := u.cfg.ActivePlace
+	u.cfg.Places = slices.DeleteFunc(u.cfg.Places, func(p Place) bool { return p.Name == name })
+	u.cfg.ActivePlace = ""
+	u.saveConfig()
+	u.out.SetText("Removed " + name + ".")
+	u.syncSSIDWatch()
+	u.locationChanged()
+}
+
+// syncSSIDWatch runs the Wi-Fi watcher only while some place lists a
+// network to match against.
+func (u *uiState) syncSSIDWatch() {
+	want := slices.ContainsFunc(u.cfg.Places, func(p Place) bool { return len(p.SSIDs) > 0 })
+	if want == (u.ssidStop != nil) {
+		return
+	}
+	if !want {
+		close(u.ssidStop)
+		u.ssidStop = nil
+		return
+	}
+	stop := make(chan struct{})
+	u.ssidStop = stop
+	go func() {
+		tick := time.NewTicker(ssidPollInterval)
+		defer tick.Stop()
+		for {
+			ssids := connectedSSIDs()
+			fyne.Do(func() {
+				if u.ssidStop == stop {
+					u.matchPlace(ssids)
+				}
+			})
+			select {
+			case <-stop:
+				return
+			case <-tick.C:
+			}
+		}
+	}()
+}
+
+func (u *uiState) matchPlace(ssids []string) {
+	for _, p := range u.cfg.Places {
+		for _, s := range p.SSIDs {
+			if slices.Contains(ssids, s) {
+				u.setActivePlace(p.Name)
+				return
+			}
+		}
+	}
+}
+
+// connectedSSIDs asks NetworkManager for the networks of all active Wi-Fi
+// connections.
+func connectedSSIDs() []string {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return nil
+	}
+	const nm = "org.freedesktop.NetworkManager"
+	v, err := conn.Object(nm, "/org/freedesktop/NetworkManager").GetProperty(nm + ".ActiveConnections")
+	if err != nil {
+		return nil
+	}
+	paths, _ := v.Value().([]dbus.ObjectPath)
+
+	var ssids []string
+	for _, path := range paths {
+		active := conn.Object(nm, path)
+		typ, err := active.GetProperty(nm + ".Connection.Active.Type")
+		if err != nil || typ.Value() != "802-11-wireless" {
+			continue
+		}
+		ap, err := active.GetProperty(nm + ".Connection.Active.SpecificObject")
+		if err != nil {
+			continue
+		}
+		apPath, _ := ap.Value().(dbus.ObjectPath)
+		ssid, err := conn.Object(nm, apPath).GetProperty(nm + ".AccessPoint.Ssid")
+		if b, ok := ssid.Value().([]byte); err == nil && ok {
+			ssids = append(ssids, string(b))
+		}
+	}
+	return ssids
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+const pomodoroOverride = "Pomodoro break"
+
+// togglePomodoro starts or stops the built-in pomodoro cycle. Focus periods
+// leave the screen alone; breaks warm and dim it slightly.
+func (u *uiState) togglePomodoro() {
+	if u.pomodoroTimer != nil {
+		u.pomodoroTimer.Stop()
+		u.pomodoroTimer = nil
+		u.pomodoroRun++
+		u.popOverride(pomodoroOverride)
+		u.out.SetText("Pomodoro stopped.")
+		return
+	}
+	u.pomodoroFocus()
+}
+
+// pomodoroNext runs phase after d unless the cycle was stopped meanwhile.
+func (u *uiState) pomodoroNext(d time.Duration, phase func()) {
+	run := u.pomodoroRun
+	u.pomodoroTimer = time.AfterFunc(d, func() {
+		fyne.Do(func() {
+			if u.pomodoroRun == run && u.pomodoroTimer != nil {
+				phase()
+			}
+		})
+	})
+}
+
+func (u *uiState) pomodoroFocus() {
+	d := time.Duration(u.cfg.PomodoroFocusMinutes) * time.Minute
+	u.popOverride(pomodoroOverride)
+	u.out.SetText("Pomodoro: focus until " + time.Now().Add(d).Format("15:04") + ".")
+	if u.pomodoroTimer != nil { // not the first round
+		fyne.CurrentApp().SendNotification(fyne.NewNotification("Back to focus", "Break is over."))
+	}
+	u.pomodoroNext(d, u.pomodoroBreak)
+}
+
+func (u *uiState) pomodoroBreak() {
+	d := time.Duration(u.cfg.PomodoroBreakMinutes) * time.Minute
+	s := u.target()
+	s.TempK -= u.cfg.PomodoroWarmK
+	s.Brightness = math.Max(s.Brightness*u.cfg.PomodoroDim, 0.10)
+	u.pushTimedOverride(pomodoroOverride, s, d)
+	fyne.CurrentApp().SendNotification(fyne.NewNotification("Pomodoro break",
+		fmt.Sprintf("Take %d minutes off.", u.cfg.PomodoroBreakMinutes)))
+	u.pomodoroNext(d, u.pomodoroFocus)
+}
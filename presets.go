@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+)
+
+// showSavePreset saves the sliders as a named preset. Saving under an
+// existing name replaces that preset in place.
+func (u *uiState) showSavePreset() {
+	name := widget.NewEntry()
+	name.SetPlaceHolder("e.g. Evening")
+	if active := u.activePreset(); active != "" {
+		name.SetText(active)
+	}
+	dialog.ShowForm("Save preset", "Save", "Cancel", []*widget.FormItem{widget.NewFormItem("Name", name)}, func(ok bool) {
+		n := strings.TrimSpace(name.Text)
+		if !ok || n == "" {
+			return
+		}
+		u.savePreset(Preset{Name: n, Settings: u.current()})
+		u.out.SetText("Saved preset " + n + ".")
+	}, u.win)
+}
+
+// savePreset stores p, replacing the preset of the same name in place.
+func (u *uiState) savePreset(p Preset) {
+	ps := slices.Clone(u.cfg.Presets)
+	if i := slices.IndexFunc(ps, func(q Preset) bool { return q.Name == p.Name }); i >= 0 {
+		ps[i] = p
+	} else {
+		ps = append(ps, p)
+	}
+	u.setPresets(ps)
+}
+
+// deletePreset removes the preset called name, and takes it off any
+// hotkey slot it was on.
+func (u *uiState) deletePreset(name string) {
+	for i, n := range u.cfg.Slots {
+		if n == name {
+			u.cfg.Slots[i] = ""
+		}
+	}
+	u.setPresets(slices.DeleteFunc(slices.Clone(u.cfg.Presets), func(p Preset) bool { return p.Name == name }))
+	u.syncHotkeys()
+	u.out.SetText("Deleted preset " + name + ".")
+}
+
+// setPresets stores ps and refreshes everything that lists them.
+func (u *uiState) setPresets(ps []Preset) {
+	u.cfg.Presets = ps
+	u.saveConfig()
+	u.syncTray()
+}
+
+// presetFile is the JSON that presets are exported to and imported from.
+type presetFile struct {
+	Presets []Preset `json:"presets"`
+}
+
+// exportPresets writes every preset to a JSON file for backup or another
+// machine.
+func (u *uiState) exportPresets() {
+	ps := u.presets()
+	if len(ps) == 0 {
+		u.out.SetText("No presets to export.")
+		return
+	}
+	d := dialog.NewFileSave(func(wc fyne.URIWriteCloser, err error) {
+		if err != nil || wc == nil {
+			return
+		}
+		defer wc.Close()
+		enc := json.NewEncoder(wc)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(presetFile{ps}); err != nil {
+			u.out.SetText("Export error: " + err.Error())
+			return
+		}
+		u.out.SetText(fmt.Sprintf("Exported %d presets to %s.", len(ps), wc.URI().Name()))
+	}, u.win)
+	d.SetFileName("presets.json")
+	d.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+	d.Show()
+}
+
+// importPresets adds the presets from a file written by exportPresets.
+// Ones named like an existing preset replace it; values outside the
+// slider ranges are clamped.
+func (u *uiState) importPresets() {
+	d := dialog.NewFileOpen(func(rc fyne.URIReadCloser, err error) {
+		if err != nil || rc == nil {
+			return
+		}
+		defer rc.Close()
+		var f presetFile
+		if err := json.NewDecoder(rc).Decode(&f); err != nil {
+			u.out.SetText("Import error: " + err.Error())
+			return
+		}
+		ps := slices.Clone(u.cfg.Presets)
+		r := u.ranges()
+		n := 0
+		for _, p := range f.Presets {
+			if p.Name = strings.TrimSpace(p.Name); p.Name == "" {
+				continue
+			}
+			p.Settings = r.clamp(p.Settings)
+			if i := slices.IndexFunc(ps, func(q Preset) bool { return q.Name == p.Name }); i >= 0 {
+				ps[i] = p
+			} else {
+				ps = append(ps, p)
+			}
+			n++
+		}
+		u.setPresets(ps)
+		u.out.SetText(fmt.Sprintf("Imported %d presets from %s.", n, rc.URI().Name()))
+	}, u.win)
+	d.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+	d.Show()
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// previewStep is the spacing of the points the schedule chart plots.
+const previewStep = 10 * time.Minute
+
+var (
+	previewTempColor   = color.NRGBA{R: 0xff, G: 0x9a, B: 0x3c, A: 0xff}
+	previewBrightColor = color.NRGBA{R: 0x7c, G: 0xc4, B: 0xff, A: 0xff}
+	previewGridColor   = color.NRGBA{R: 0x60, G: 0x60, B: 0x60, A: 0xff}
+)
+
+// scheduleDay returns what week sets every previewStep through the
+// calendar day of now, starting at midnight.
+func scheduleDay(week weekSchedule, loc *Location, blend bool, now time.Time) []Settings {
+	y, m, d := now.Date()
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+	var day []Settings
+	for t := midnight; !t.After(midnight.AddDate(0, 0, 1)); t = t.Add(previewStep) {
+		s := defaultSettings
+		if cur, next, _ := scheduleAround(week, loc, t); cur != nil {
+			s = cur.settings
+			if blend {
+				s = scheduleBlend(cur, next, t)
+			}
+		}
+		day = append(day, s)
+	}
+	return day
+}
+
+// chartLayout places a day's temperature and brightness lines, the hour
+// grid and the "now" marker in whatever size the chart is given.
+// Temperature is scaled to the slider range, brightness from 0 to 1.
+type chartLayout struct {
+	day          []Settings
+	r            Ranges
+	now          float64 // fraction of the day gone
+	temp, bright []*canvas.Line
+	grid         []*canvas.Line
+	hours        []*canvas.Text
+	marker       *canvas.Line
+}
+
+func newChart(day []Settings, r Ranges, now float64) *fyne.Container {
+	l := &chartLayout{day: day, r: r, now: now,
+		marker: canvas.NewLine(color.White)}
+	var objs []fyne.CanvasObject
+	for h := 0; h <= 24; h += 6 {
+		g := canvas.NewLine(previewGridColor)
+		t := canvas.NewText(fmt.Sprintf("%02d:00", h%24), previewGridColor)
+		t.TextSize = 10
+		l.grid, l.hours = append(l.grid, g), append(l.hours, t)
+		objs = append(objs, g, t)
+	}
+	for range day[1:] {
+		t, b := canvas.NewLine(previewTempColor), canvas.NewLine(previewBrightColor)
+		t.StrokeWidth, b.StrokeWidth = 2, 2
+		l.temp, l.bright = append(l.temp, t), append(l.bright, b)
+		objs = append(objs, t, b)
+	}
+	l.marker.StrokeWidth = 1
+	objs = append(objs, l.marker)
+	return container.New(l, objs...)
+}
+
+func (l *chartLayout) Layout(_ []fyne.CanvasObject, size fyne.Size) {
+	const labels = 14 // room for the hour labels below the plot
+	h := size.Height - labels
+	x := func(i int) float32 { return size.Width * float32(i) / float32(len(l.day)-1) }
+	y := func(v float64) float32 { return h * float32(1-min(max(v, 0), 1)) }
+	tempY := func(s Settings) float32 {
+		return y(float64(s.TempK-l.r.TempMin) / float64(l.r.TempMax-l.r.TempMin))
+	}
+	for i := range l.temp {
+		l.temp[i].Position1 = fyne.NewPos(x(i), tempY(l.day[i]))
+		l.temp[i].Position2 = fyne.NewPos(x(i+1), tempY(l.day[i+1]))
+		l.bright[i].Position1 = fyne.NewPos(x(i), y(l.day[i].Brightness))
+		l.bright[i].Position2 = fyne.NewPos(x(i+1), y(l.day[i+1].Brightness))
+	}
+	for i, g := range l.grid {
+		gx := size.Width * float32(i) / float32(len(l.grid)-1)
+		g.Position1, g.Position2 = fyne.NewPos(gx, 0), fyne.NewPos(gx, h)
+		t := l.hours[i]
+		w := t.MinSize().Width
+		t.Move(fyne.NewPos(min(max(gx-w/2, 0), size.Width-w), h))
+		t.Resize(t.MinSize())
+	}
+	mx := size.Width * float32(l.now)
+	l.marker.Position1, l.marker.Position2 = fyne.NewPos(mx, 0), fyne.NewPos(mx, h)
+}
+
+func (l *chartLayout) MinSize(_ []fyne.CanvasObject) fyne.Size {
+	return fyne.NewSize(480, 200)
+}
+
+// showSchedulePreview charts what week would do today, so a schedule can
+// be checked before it is followed.
+func (u *uiState) showSchedulePreview(week weekSchedule, blend bool) {
+	var loc *Location
+	if l, ok := u.location(); ok {
+		loc = &l
+	}
+	now := time.Now()
+	y, m, d := now.Date()
+	gone := now.Sub(time.Date(y, m, d, 0, 0, 0, 0, now.Location())).Hours() / 24
+	r := u.ranges()
+	legend := func(c color.Color, s string) fyne.CanvasObject {
+		t := canvas.NewText(s, c)
+		t.TextSize = 12
+		return t
+	}
+	content := container.NewBorder(
+		container.NewHBox(
+			legend(previewTempColor, fmt.Sprintf("Temperature (%d–%d K)", r.TempMin, r.TempMax)),
+			legend(previewBrightColor, "Brightness"),
+			legend(color.White, "Now")),
+		nil, nil, nil,
+		newChart(scheduleDay(week, loc, blend, now), r, gone))
+	if loc == nil {
+		content = container.NewBorder(nil, widget.NewLabel("Solar entries are left out until a location is set."), nil, nil, content)
+	}
+	dialog.ShowCustom("Today's schedule", "Close", content, u.win)
+}
+
+// previewSchedule charts the saved schedule.
+func (u *uiState) previewSchedule() {
+	u.showSchedulePreview(u.week(), u.cfg.ScheduleBlend)
+}
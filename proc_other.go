@@ -0,0 +1,8 @@
+//go:build !linux
+
+package main
+
+import "os/exec"
+
+// dieWithParent is best effort: only Linux can tie a child's life to ours.
+func dieWithParent(*exec.Cmd) {}
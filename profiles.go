@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// activityProfile is a built-in choice offered above the sliders, so
+// there is something sensible to pick before any presets are saved. The
+// values are the ones the matching modes already use and tune.
+type activityProfile struct {
+	name     string
+	settings func(c *Config) Settings // nil for Gaming, which is a mode of its own
+}
+
+var activityProfiles = []activityProfile{
+	{"Reading", func(c *Config) Settings { return c.ReadingPreset }},
+	{"Movie", func(c *Config) Settings { return c.MoviePreset }},
+	{"Gaming", nil},
+	{"Work", func(c *Config) Settings { return c.WorkPreset }},
+}
+
+// applyProfile fades to p's values, or toggles Gaming mode for Gaming.
+func (u *uiState) applyProfile(p activityProfile) {
+	if p.settings == nil {
+		u.toggleGaming()
+		return
+	}
+	u.applyPreset(Preset{p.name, p.settings(&u.cfg)})
+}
+
+// newProfileBar makes one button per activity profile; syncProfiles
+// marks the one in effect.
+func (u *uiState) newProfileBar() fyne.CanvasObject {
+	u.profiles = nil
+	grid := container.NewGridWithColumns(len(activityProfiles))
+	for _, p := range activityProfiles {
+		b := widget.NewButton(p.name, func() { u.applyProfile(p) })
+		u.profiles = append(u.profiles, b)
+		grid.Add(b)
+	}
+	u.syncProfiles()
+	return grid
+}
+
+// activeProfile names the activity profile in effect, or "".
+func (u *uiState) activeProfile() string {
+	if o, ok := u.activeOverride(); ok {
+		if o.name == gamingOverride {
+			return "Gaming"
+		}
+		return ""
+	}
+	cur := u.current()
+	for _, p := range activityProfiles {
+		if p.settings != nil && p.settings(&u.cfg) == cur {
+			return p.name
+		}
+	}
+	return ""
+}
+
+// syncProfiles marks the active profile in the bar and the dropdown.
+func (u *uiState) syncProfiles() {
+	active := u.activeProfile()
+	if u.profileSelect != nil && u.profileSelect.Selected != active {
+		u.profileSelect.Selected = active // not SetSelected, which would apply it again
+		u.profileSelect.Refresh()
+	}
+	for i, b := range u.profiles {
+		imp := widget.MediumImportance
+		if activityProfiles[i].name == active {
+			imp = widget.HighImportance
+		}
+		if b.Importance != imp {
+			b.Importance = imp
+			b.Refresh()
+		}
+	}
+}
+
+func (u *uiState) useForWork() {
+	u.cfg.WorkPreset = u.current()
+	u.saveConfig()
+	u.syncProfiles()
+	u.out.SetText("Work preset updated.")
+}
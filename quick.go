@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+// QuickAction is a relative change held for a while, such as "Warmer for
+// 30 minutes" when a headache sets in.
+type QuickAction struct {
+	Name            string  `json:"name"`
+	TempDeltaK      int     `json:"temp_delta_k"`
+	BrightnessDelta float64 `json:"brightness_delta"`
+	Minutes         int     `json:"minutes"`
+}
+
+var defaultQuickActions = []QuickAction{
+	{Name: "Warmer for 30 minutes", TempDeltaK: -500, Minutes: 30},
+	{Name: "Dimmer for 30 minutes", BrightnessDelta: -0.10, Minutes: 30},
+	{Name: "Warmer and dimmer for an hour", TempDeltaK: -1000, BrightnessDelta: -0.20, Minutes: 60},
+}
+
+// shifted returns s moved by the given deltas; callers clamp the result.
+func (s Settings) shifted(tempK int, brightness float64) Settings {
+	s.TempK += tempK
+	s.Brightness += brightness
+	return s
+}
+
+// runQuickAction applies q on top of what is on screen now and reverts it
+// when its time is up. Running it again restarts the countdown from the
+// settings underneath rather than stacking the change twice.
+func (u *uiState) runQuickAction(q QuickAction) {
+	u.removeOverride(q.Name)
+	s := u.ranges().clamp(u.target().shifted(q.TempDeltaK, q.BrightnessDelta))
+	u.pushTimedOverride(q.Name, s, time.Duration(q.Minutes)*time.Minute)
+	u.out.SetText(fmt.Sprintf("%s (%d K, brightness %s).", q.Name, s.TempK, formatNum("%.2f", s.Brightness)))
+}
+
+// quickMenu lists the configured quick actions.
+func (u *uiState) quickMenu() *fyne.Menu {
+	items := make([]*fyne.MenuItem, len(u.cfg.QuickActions))
+	for i, q := range u.cfg.QuickActions {
+		items[i] = fyne.NewMenuItem(q.Name, func() { u.runQuickAction(q) })
+		items[i].Checked = u.hasOverride(q.Name)
+	}
+	return fyne.NewMenu("Quick actions", items...)
+}
@@ -0,0 +1,198 @@
+package main
+
+import "math"
+
+// Ramp is one gamma lookup table per channel, 16 bit like the X server's.
+type Ramp struct {
+	R, G, B []uint16
+}
+
+// Size returns the number of entries per channel.
+func (r Ramp) Size() int { return len(r.R) }
+
+// identityRamp returns a linear ramp with size entries per channel.
+func identityRamp(size int) Ramp {
+	r := Ramp{R: make([]uint16, size), G: make([]uint16, size), B: make([]uint16, size)}
+	for i := 0; i < size; i++ {
+		v := uint16(math.Round(float64(i) / float64(size-1) * math.MaxUint16))
+		r.R[i], r.G[i], r.B[i] = v, v, v
+	}
+	return r
+}
+
+// refCurve is the transfer function the display is taken to have when
+// ramps are computed in linear light.
+type refCurve int32
+
+const (
+	refNone    refCurve = iota // scale encoded values, as redshift does
+	refSRGB                    // content is sRGB, the panel a pure 2.2
+	refGamma22                 // content and panel are both a pure 2.2
+)
+
+var refCurveNames = map[refCurve]string{refNone: "", refSRGB: "srgb", refGamma22: "gamma22"}
+
+// decode converts an encoded value to linear light.
+func (c refCurve) decode(v float64) float64 {
+	switch c {
+	case refSRGB:
+		if v <= 0.04045 {
+			return v / 12.92
+		}
+		return math.Pow((v+0.055)/1.055, 2.4)
+	case refGamma22:
+		return math.Pow(v, 2.2)
+	}
+	return v
+}
+
+// scale multiplies the encoded value v by k in the light domain of c and
+// re-encodes it for a 2.2 panel. With refNone it is a plain multiply.
+func (c refCurve) scale(v, k float64) float64 {
+	if c == refNone {
+		return v * k
+	}
+	return math.Pow(c.decode(v)*k, 1/2.2)
+}
+
+// computeRamp returns the ramps for s, the same way redshift builds them:
+// each channel is scaled by the white point and brightness, then raised to
+// 1/gamma. When base is non-nil it is the starting curve instead of a linear
+// ramp, so an imported calibration stays underneath the adjustments.
+func computeRamp(s Settings, base *Ramp, size int) Ramp {
+	return computeRampRef(s, base, refNone, size)
+}
+
+// computeRampRef is computeRamp with the white point and brightness
+// applied in the linear light of ref. For refSRGB even neutral settings
+// change the curve: the result is the sRGB tone curve on a 2.2 panel.
+func computeRampRef(s Settings, base *Ramp, ref refCurve, size int) Ramp {
+	wr, wg, wb := s.white()
+	gr, gg, gb := s.channelGamma() // blue reduction bends blue, keeping white white
+
+	out := Ramp{R: make([]uint16, size), G: make([]uint16, size), B: make([]uint16, size)}
+	for i := 0; i < size; i++ {
+		in := float64(i) / float64(size-1)
+		br, bg, bb := in, in, in
+		if base != nil {
+			br, bg, bb = base.sample(base.R, in), base.sample(base.G, in), base.sample(base.B, in)
+		}
+		out.R[i] = rampValue(ref.scale(br, s.Brightness*wr), gr)
+		out.G[i] = rampValue(ref.scale(bg, s.Brightness*wg), gg)
+		out.B[i] = rampValue(ref.scale(bb, s.Brightness*wb), gb)
+	}
+	return out
+}
+
+// sample reads channel c at position v (0..1), interpolating between
+// entries so ramps of different sizes can be combined.
+func (r Ramp) sample(c []uint16, v float64) float64 {
+	pos := v * float64(len(c)-1)
+	i := int(pos)
+	if i >= len(c)-1 {
+		return float64(c[len(c)-1]) / math.MaxUint16
+	}
+	frac := pos - float64(i)
+	return (float64(c[i])*(1-frac) + float64(c[i+1])*frac) / math.MaxUint16
+}
+
+func rampValue(v, gamma float64) uint16 {
+	v = math.Pow(math.Min(math.Max(v, 0), 1), 1/gamma)
+	return uint16(math.Round(v * math.MaxUint16))
+}
+
+// white returns the channel multipliers for s: its xy white point if it
+// has one, otherwise the blackbody at TempK moved off the locus by Tint.
+func (s Settings) white() (r, g, b float64) {
+	if s.hasWhiteXY() || s.Tint != 0 {
+		return normalizeWhite(xyRGB(s.whiteXY()))
+	}
+	return whitePoint(s.TempK)
+}
+
+// whitePoint returns the relative RGB multipliers for a blackbody at tempK,
+// normalized so 6500K is neutral and the strongest channel is 1.
+func whitePoint(tempK int) (r, g, b float64) {
+	return normalizeWhite(blackbodyRGB(float64(tempK)))
+}
+
+// normalizeWhite scales linear RGB relative to the 6500K blackbody so the
+// strongest channel is 1.
+func normalizeWhite(r, g, b float64) (float64, float64, float64) {
+	nr, ng, nb := blackbodyRGB(6500)
+	r, g, b = r/nr, g/ng, b/nb
+	m := math.Max(r, math.Max(g, b))
+	return r / m, g / m, b / m
+}
+
+// blackbodyRGB converts a color temperature to linear sRGB.
+func blackbodyRGB(t float64) (r, g, b float64) {
+	return xyRGB(planckianXY(t))
+}
+
+// planckianXY returns the chromaticity of a blackbody at t using the Kim
+// et al. cubic spline approximation of the Planckian locus. The spline is
+// only defined from 1667K to 25000K, so t is clamped to that range.
+func planckianXY(t float64) (x, y float64) {
+	t = math.Min(math.Max(t, 1667), 25000)
+
+	if t <= 4000 {
+		x = -0.2661239e9/(t*t*t) - 0.2343589e6/(t*t) + 0.8776956e3/t + 0.179910
+	} else {
+		x = -3.0258469e9/(t*t*t) + 2.1070379e6/(t*t) + 0.2226347e3/t + 0.240390
+	}
+
+	switch {
+	case t <= 2222:
+		y = -1.1063814*x*x*x - 1.34811020*x*x + 2.18555832*x - 0.20219683
+	case t <= 4000:
+		y = -0.9549476*x*x*x - 1.37418593*x*x + 2.09137015*x - 0.16748867
+	default:
+		y = 3.0817580*x*x*x - 5.87338670*x*x + 3.75112997*x - 0.37001483
+	}
+	return x, y
+}
+
+// tintMax is the largest Duv the tint slider offers; well past it the
+// white no longer reads as white.
+const tintMax = 0.02
+
+// tintedXY returns the chromaticity duv away from the blackbody at t,
+// measured perpendicular to the locus in CIE 1960 uv. Positive duv is
+// above the locus (greener), negative below it (more magenta).
+func tintedXY(t, duv float64) (x, y float64) {
+	x, y = planckianXY(t)
+	if duv == 0 {
+		return x, y
+	}
+	u, v := xyToUV(x, y)
+	u1, v1 := xyToUV(planckianXY(t - 10))
+	u2, v2 := xyToUV(planckianXY(t + 10))
+	nu, nv := -(v2 - v1), u2-u1
+	if nv < 0 {
+		nu, nv = -nu, -nv
+	}
+	n := math.Hypot(nu, nv)
+	return uvToXY(u+duv*nu/n, v+duv*nv/n)
+}
+
+func xyToUV(x, y float64) (u, v float64) {
+	d := -2*x + 12*y + 3
+	return 4 * x / d, 6 * y / d
+}
+
+func uvToXY(u, v float64) (x, y float64) {
+	d := 2*u - 8*v + 4
+	return 3 * u / d, 2 * v / d
+}
+
+// xyRGB converts the chromaticity x, y at Y = 1 to linear sRGB, clipping
+// negative channels.
+func xyRGB(x, y float64) (r, g, b float64) {
+	// xyY (Y = 1) -> XYZ -> linear sRGB
+	X, Y, Z := x/y, 1.0, (1-x-y)/y
+	r = 3.2406*X - 1.5372*Y - 0.4986*Z
+	g = -0.9689*X + 1.8758*Y + 0.0415*Z
+	b = 0.0557*X - 0.2040*Y + 1.0570*Z
+	return math.Max(r, 0), math.Max(g, 0), math.Max(b, 0)
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ---- CSV: one row per entry, "index,red,green,blue" ----
+
+// writeRampCSV separates the fields with csvComma, so the file opens in
+// the user's spreadsheet.
+func writeRampCSV(w io.Writer, r Ramp) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = csvComma
+	cw.Write([]string{"index", "red", "green", "blue"})
+	for i := 0; i < r.Size(); i++ {
+		cw.Write([]string{
+			strconv.Itoa(i),
+			strconv.Itoa(int(r.R[i])),
+			strconv.Itoa(int(r.G[i])),
+			strconv.Itoa(int(r.B[i])),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// readRampCSV accepts either 16-bit integer values or 0..1 floats, with or
+// without the index column and header row. The choice is made once for
+// the whole file: floats if any value has a point or none is above 1, so
+// the 0 and 1 a float writer leaves bare at the ends aren't read as
+// integers. Files separated by semicolons may use a decimal comma.
+func readRampCSV(rd io.Reader) (Ramp, error) {
+	data, err := io.ReadAll(rd)
+	if err != nil {
+		return Ramp{}, err
+	}
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	semicolons := bytes.ContainsRune(firstLine, ';')
+	if semicolons {
+		cr.Comma = ';'
+	}
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return Ramp{}, err
+	}
+	if semicolons {
+		for _, row := range rows {
+			for i, field := range row {
+				row[i] = strings.Replace(field, ",", ".", 1)
+			}
+		}
+	}
+
+	first := 1 // line number of rows[0]
+	if len(rows) > 0 && !numericRow(rows[0]) {
+		rows, first = rows[1:], 2 // header row
+	}
+	point, over1 := false, false
+	for n, row := range rows {
+		if len(row) > 3 {
+			row = row[len(row)-3:] // drop the index column
+		}
+		if len(row) != 3 {
+			return Ramp{}, fmt.Errorf("line %d: expected 3 channel values", n+first)
+		}
+		rows[n] = row
+		for _, field := range row {
+			point = point || strings.Contains(field, ".")
+			if f, err := strconv.ParseFloat(field, 64); err == nil && f > 1 {
+				over1 = true
+			}
+		}
+	}
+	floats := point || !over1
+
+	var r Ramp
+	for n, row := range rows {
+		var vals [3]uint16
+		for c, field := range row {
+			if vals[c], err = parseRampValue(field, floats); err != nil {
+				return Ramp{}, fmt.Errorf("line %d: %w", n+first, err)
+			}
+		}
+		r.R = append(r.R, vals[0])
+		r.G = append(r.G, vals[1])
+		r.B = append(r.B, vals[2])
+	}
+	if r.Size() < 2 {
+		return Ramp{}, errors.New("ramp needs at least 2 entries")
+	}
+	return r, nil
+}
+
+// numericRow reports whether every field of row parses as a number.
+func numericRow(row []string) bool {
+	for _, field := range row {
+		if _, err := strconv.ParseFloat(field, 64); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func parseRampValue(s string, float bool) (uint16, error) {
+	if !float {
+		v, err := strconv.ParseUint(s, 10, 16)
+		return uint16(v), err
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	if f < 0 || f > 1 {
+		return 0, fmt.Errorf("value %s outside 0..1", s)
+	}
+	return uint16(math.Round(f * math.MaxUint16)), nil
+}
+
+// ---- ICC: only the vcgt (video card gamma) tag is read or written ----
+
+var iccTagVCGT = [4]byte{'v', 'c', 'g', 't'}
+
+// writeRampICC writes a minimal display profile carrying r as its vcgt
+// tag, which is what dispwin, colord and friends load into the video card.
+func writeRampICC(w io.Writer, r Ramp, desc string) error {
+	type tag struct {
+		sig  string
+		data []byte
+	}
+	tags := []tag{
+		{"desc", iccTextDescription(desc)},
+		{"cprt", iccText("No copyright, use freely")},
+		{"wtpt", iccXYZ(0.9642, 1.0, 0.8249)}, // D50
+		{"vcgt", iccVCGT(r)},
+	}
+
+	const headerLen = 128
+	offset := headerLen + 4 + 12*len(tags)
+	var table, body bytes.Buffer
+	binary.Write(&table, binary.BigEndian, uint32(len(tags)))
+	for _, t := range tags {
+		for offset%4 != 0 {
+			offset++
+			body.WriteByte(0)
+		}
+		table.WriteString(t.sig)
+		binary.Write(&table, binary.BigEndian, uint32(offset))
+		binary.Write(&table, binary.BigEndian, uint32(len(t.data)))
+		body.Write(t.data)
+		offset += len(t.data)
+	}
+
+	now := time.Now().UTC()
+	h := make([]byte, headerLen)
+	binary.BigEndian.PutUint32(h[0:], uint32(headerLen+table.Len()+body.Len()))
+	binary.BigEndian.PutUint32(h[8:], 0x02100000) // version 2.1
+	copy(h[12:], "mntr")
+	copy(h[16:], "RGB ")
+	copy(h[20:], "XYZ ")
+	for i, v := range []int{now.Year(), int(now.Month()), now.Day(), now.Hour(), now.Minute(), now.Second()} {
+		binary.BigEndian.PutUint16(h[24+2*i:], uint16(v))
+	}
+	copy(h[36:], "acsp")
+	copy(h[68:], iccXYZ(0.9642, 1.0, 0.8249)[8:]) // PCS illuminant
+
+	for _, part := range [][]byte{h, table.Bytes(), body.Bytes()} {
+		if _, err := w.Write(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readRampICC(rd io.Reader) (Ramp, error) {
+	data, err := io.ReadAll(rd)
+	if err != nil {
+		return Ramp{}, err
+	}
+	if len(data) < 132 || string(data[36:40]) != "acsp" {
+		return Ramp{}, errors.New("not an ICC profile")
+	}
+	count := int(binary.BigEndian.Uint32(data[128:]))
+	for i := 0; i < count; i++ {
+		entry := 132 + 12*i
+		if entry+12 > len(data) {
+			break
+		}
+		if [4]byte(data[entry:entry+4]) != iccTagVCGT {
+			continue
+		}
+		off := int(binary.BigEndian.Uint32(data[entry+4:]))
+		size := int(binary.BigEndian.Uint32(data[entry+8:]))
+		if off+size > len(data) || size < 12 {
+			return Ramp{}, errors.New("truncated vcgt tag")
+		}
+		return parseVCGT(data[off : off+size])
+	}
+	return Ramp{}, errors.New("profile has no vcgt tag")
+}
+
+func parseVCGT(tag []byte) (Ramp, error) {
+	switch binary.BigEndian.Uint32(tag[8:]) {
+	case 0: // table
+		if len(tag) < 18 {
+			return Ramp{}, errors.New("truncated vcgt table")
+		}
+		channels := int(binary.BigEndian.Uint16(tag[12:]))
+		entries := int(binary.BigEndian.Uint16(tag[14:]))
+		width := int(binary.BigEndian.Uint16(tag[16:]))
+		data := tag[18:]
+		if (channels != 1 && channels != 3) || (width != 1 && width != 2) || entries < 2 {
+			return Ramp{}, errors.New("unsupported vcgt table layout")
+		}
+		if len(data) < channels*entries*width {
+			return Ramp{}, errors.New("truncated vcgt table")
+		}
+		read := func(c, i int) uint16 {
+			p := (c*entries + i) * width
+			if width == 1 {
+				return uint16(data[p]) * 257
+			}
+			return binary.BigEndian.Uint16(data[p:])
+		}
+		r := Ramp{R: make([]uint16, entries), G: make([]uint16, entries), B: make([]uint16, entries)}
+		for i := 0; i < entries; i++ {
+			r.R[i] = read(0, i)
+			r.G[i], r.B[i] = r.R[i], r.R[i]
+			if channels == 3 {
+				r.G[i], r.B[i] = read(1, i), read(2, i)
+			}
+		}
+		return r, nil
+
+	case 1: // formula: gamma, min, max per channel as s15Fixed16
+		if len(tag) < 12+36 {
+			return Ramp{}, errors.New("truncated vcgt formula")
+		}
+		fixed := func(i int) float64 {
+			return float64(int32(binary.BigEndian.Uint32(tag[12+4*i:]))) / 65536
+		}
+		r := identityRamp(256)
+		for c, ch := range [][]uint16{r.R, r.G, r.B} {
+			gamma, lo, hi := fixed(3*c), fixed(3*c+1), fixed(3*c+2)
+			for i := range ch {
+				v := lo + (hi-lo)*math.Pow(float64(i)/255, gamma)
+				ch[i] = uint16(math.Round(math.Min(math.Max(v, 0), 1) * math.MaxUint16))
+			}
+		}
+		return r, nil
+	}
+	return Ramp{}, errors.New("unknown vcgt type")
+}
+
+func iccVCGT(r Ramp) []byte {
+	var b bytes.Buffer
+	b.Write(iccTagVCGT[:])
+	binary.Write(&b, binary.BigEndian, uint32(0)) // reserved
+	binary.Write(&b, binary.BigEndian, uint32(0)) // table type
+	binary.Write(&b, binary.BigEndian, uint16(3))
+	binary.Write(&b, binary.BigEndian, uint16(r.Size()))
+	binary.Write(&b, binary.BigEndian, uint16(2))
+	for _, ch := range [][]uint16{r.R, r.G, r.B} {
+		binary.Write(&b, binary.BigEndian, ch)
+	}
+	return b.Bytes()
+}
+
+func iccXYZ(x, y, z float64) []byte {
+	b := make([]byte, 20)
+	copy(b, "XYZ ")
+	for i, v := range []float64{x, y, z} {
+		binary.BigEndian.PutUint32(b[8+4*i:], uint32(int32(math.Round(v*65536))))
+	}
+	return b
+}
+
+func iccText(s string) []byte {
+	b := make([]byte, 8, 9+len(s))
+	copy(b, "text")
+	return append(append(b, s...), 0)
+}
+
+func iccTextDescription(s string) []byte {
+	var b bytes.Buffer
+	b.WriteString("desc")
+	b.Write(make([]byte, 4))
+	binary.Write(&b, binary.BigEndian, uint32(len(s)+1))
+	b.WriteString(s)
+	b.WriteByte(0)
+	b.Write(make([]byte, 4+4))    // no Unicode description
+	b.Write(make([]byte, 2+1+67)) // no ScriptCode description
+	return b.Bytes()
+}
+
+// ---- format selection by file extension ----
+
+func isICCFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".icc" || ext == ".icm"
+}
+
+func writeRampFile(name string, w io.Writer, r Ramp) error {
+	if isICCFile(name) {
+		return writeRampICC(w, r, strings.TrimSuffix(filepath.Base(name), filepath.Ext(name)))
+	}
+	return writeRampCSV(w, r)
+}
+
+func readRampFile(name string, rd io.Reader) (Ramp, error) {
+	if isICCFile(name) {
+		return readRampICC(rd)
+	}
+	return readRampCSV(rd)
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/randr"
+	"github.com/jezek/xgb/xproto"
+)
+
+// seatCRTCs returns the CRTCs of the default X screen that don't show a
+// display in foreign (see seatInfo.foreignEDIDs), along with each one's
+// index in the screen resources, which is what redshift's crtc= expects.
+func seatCRTCs(X *xgb.Conn, foreign map[string]bool) (crtcs []randr.Crtc, idx []int, all bool, err error) {
+	return screenCRTCs(X, xproto.Setup(X).DefaultScreen(X).Root, foreign)
+}
+
+// screenCRTCs is seatCRTCs for the X screen whose root window is root.
+func screenCRTCs(X *xgb.Conn, root xproto.Window, foreign map[string]bool) (crtcs []randr.Crtc, idx []int, all bool, err error) {
+	if err := randr.Init(X); err != nil {
+		return nil, nil, false, err
+	}
+	res, err := randr.GetScreenResourcesCurrent(X, root).Reply()
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if len(res.Crtcs) == 0 {
+		return nil, nil, false, errors.New("no CRTCs found")
+	}
+
+	edidAtom, err := internAtom(X, "EDID")
+	if err != nil {
+		return nil, nil, false, err
+	}
+	all = true
+	for i, crtc := range res.Crtcs {
+		if len(foreign) > 0 && showsForeign(X, crtc, res.ConfigTimestamp, edidAtom, foreign) {
+			all = false
+			continue
+		}
+		crtcs = append(crtcs, crtc)
+		idx = append(idx, i)
+	}
+	return crtcs, idx, all, nil
+}
+
+func showsForeign(X *xgb.Conn, crtc randr.Crtc, ts xproto.Timestamp, edidAtom xproto.Atom, foreign map[string]bool) bool {
+	info, err := randr.GetCrtcInfo(X, crtc, ts).Reply()
+	if err != nil {
+		return false
+	}
+	for _, out := range info.Outputs {
+		p, err := randr.GetOutputProperty(X, out, edidAtom, xproto.GetPropertyTypeAny, 0, 256, false, false).Reply()
+		if err == nil && foreign[string(p.Data)] {
+			return true
+		}
+	}
+	return false
+}
+
+// screensCRTCs returns the seat CRTCs of every X screen in screens, each
+// with its index as seatCRTCs gives it, or -1 on screens other than the
+// default one, which the per-CRTC features don't reach.
+func screensCRTCs(X *xgb.Conn, screens []int, foreign map[string]bool) (crtcs []randr.Crtc, idx []int, err error) {
+	roots := xproto.Setup(X).Roots
+	for _, n := range screens {
+		if n >= len(roots) {
+			continue
+		}
+		c, i, _, err := screenCRTCs(X, roots[n].Root, foreign)
+		if err != nil {
+			return nil, nil, err
+		}
+		if n != X.DefaultScreen {
+			for k := range i {
+				i[k] = -1
+			}
+		}
+		crtcs, idx = append(crtcs, c...), append(idx, i...)
+	}
+	if len(crtcs) == 0 {
+		return nil, nil, errors.New("no CRTCs found")
+	}
+	return crtcs, idx, nil
+}
+
+// xScreenLayout returns the number of screens the X display has and
+// which is the default; 1 and 0 when it can't be reached.
+func xScreenLayout() (count, def int) {
+	X, err := xgb.NewConn()
+	if err != nil {
+		return 1, 0
+	}
+	defer X.Close()
+	return len(xproto.Setup(X).Roots), X.DefaultScreen
+}
+
+// setRandrRamps writes gamma ramps to every CRTC on our seat of the X
+// screens in screens. rampFor is called once per CRTC with that CRTC's
+// index (see screensCRTCs) and gamma table size.
+func setRandrRamps(foreign map[string]bool, screens []int, rampFor func(crtc, size int) Ramp) error {
+	X, err := xgb.NewConn()
+	if err != nil {
+		return err
+	}
+	defer X.Close()
+
+	crtcs, idx, err := screensCRTCs(X, screens, foreign)
+	if err != nil {
+		return err
+	}
+	for i, crtc := range crtcs {
+		gs, err := randr.GetCrtcGammaSize(X, crtc).Reply()
+		if err != nil {
+			return err
+		}
+		if gs.Size < 2 {
+			continue
+		}
+		r := rampFor(idx[i], int(gs.Size))
+		if err := randr.SetCrtcGammaChecked(X, crtc, gs.Size, r.R, r.G, r.B).Check(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seatMethods returns one "randr:crtc=N" redshift method per CRTC on our
+// seat, or nil when nothing needs excluding and plain randr will do.
+func seatMethods(foreign map[string]bool) []string {
+	if len(foreign) == 0 {
+		return nil
+	}
+	X, err := xgb.NewConn()
+	if err != nil {
+		return nil
+	}
+	defer X.Close()
+
+	_, idx, all, err := seatCRTCs(X, foreign)
+	if err != nil || all {
+		return nil
+	}
+	methods := make([]string, len(idx))
+	for i, n := range idx {
+		methods[i] = "randr:crtc=" + strconv.Itoa(n)
+	}
+	return methods
+}
+
+// seatCRTCIndexes returns the index of every CRTC on our seat, for callers
+// that address each one separately even when nothing is excluded.
+func seatCRTCIndexes(foreign map[string]bool) ([]int, error) {
+	X, err := xgb.NewConn()
+	if err != nil {
+		return nil, err
+	}
+	defer X.Close()
+
+	_, idx, _, err := seatCRTCs(X, foreign)
+	return idx, err
+}
+
+// crtcAt returns the index of the active CRTC whose area contains the
+// root-window point (x, y), or -1 if none does.
+func crtcAt(X *xgb.Conn, x, y int) int {
+	if err := randr.Init(X); err != nil {
+		return -1
+	}
+	root := xproto.Setup(X).DefaultScreen(X).Root
+	res, err := randr.GetScreenResourcesCurrent(X, root).Reply()
+	if err != nil {
+		return -1
+	}
+	for i, crtc := range res.Crtcs {
+		info, err := randr.GetCrtcInfo(X, crtc, res.ConfigTimestamp).Reply()
+		if err != nil || info.Width == 0 {
+			continue
+		}
+		if x >= int(info.X) && x < int(info.X)+int(info.Width) &&
+			y >= int(info.Y) && y < int(info.Y)+int(info.Height) {
+			return i
+		}
+	}
+	return -1
+}
+
+// seatGammaSizes returns the gamma table size of every CRTC on our seat.
+func seatGammaSizes(foreign map[string]bool) ([]int, error) {
+	X, err := xgb.NewConn()
+	if err != nil {
+		return nil, err
+	}
+	defer X.Close()
+
+	crtcs, _, _, err := seatCRTCs(X, foreign)
+	if err != nil {
+		return nil, err
+	}
+	var sizes []int
+	for _, crtc := range crtcs {
+		if gs, err := randr.GetCrtcGammaSize(X, crtc).Reply(); err == nil && gs.Size >= 2 {
+			sizes = append(sizes, int(gs.Size))
+		}
+	}
+	return sizes, nil
+}
+
+// seatRamps reads back the gamma ramp of every CRTC on our seat, keyed
+// by CRTC index.
+func seatRamps(foreign map[string]bool) (map[int]Ramp, error) {
+	X, err := xgb.NewConn()
+	if err != nil {
+		return nil, err
+	}
+	defer X.Close()
+
+	crtcs, idx, _, err := seatCRTCs(X, foreign)
+	if err != nil {
+		return nil, err
+	}
+	ramps := map[int]Ramp{}
+	for i, crtc := range crtcs {
+		g, err := randr.GetCrtcGamma(X, crtc).Reply()
+		if err != nil {
+			return nil, err
+		}
+		if g.Size >= 2 {
+			ramps[idx[i]] = Ramp{R: g.Red, G: g.Green, B: g.Blue}
+		}
+	}
+	return ramps, nil
+}
+
+// seatOutput is a lit output on our seat.
+type seatOutput struct {
+	name string // e.g. "HDMI-1"
+	edid string // raw EDID, "" when the output has none
+	crtc int    // index of the CRTC showing it
+
+	x, y, width, height int // its area of the root window
+}
+
+// seatOutputs lists the outputs our seat's CRTCs are showing.
+func seatOutputs(foreign map[string]bool) ([]seatOutput, error) {
+	X, err := xgb.NewConn()
+	if err != nil {
+		return nil, err
+	}
+	defer X.Close()
+
+	crtcs, idx, _, err := seatCRTCs(X, foreign)
+	if err != nil {
+		return nil, err
+	}
+	root := xproto.Setup(X).DefaultScreen(X).Root
+	res, err := randr.GetScreenResourcesCurrent(X, root).Reply()
+	if err != nil {
+		return nil, err
+	}
+	edidAtom, err := internAtom(X, "EDID")
+	if err != nil {
+		return nil, err
+	}
+	var outs []seatOutput
+	for i, crtc := range crtcs {
+		info, err := randr.GetCrtcInfo(X, crtc, res.ConfigTimestamp).Reply()
+		if err != nil {
+			continue
+		}
+		for _, out := range info.Outputs {
+			oi, err := randr.GetOutputInfo(X, out, res.ConfigTimestamp).Reply()
+			if err != nil {
+				continue
+			}
+			o := seatOutput{name: string(oi.Name), crtc: idx[i],
+				x: int(info.X), y: int(info.Y), width: int(info.Width), height: int(info.Height)}
+			if p, err := randr.GetOutputProperty(X, out, edidAtom, xproto.GetPropertyTypeAny, 0, 256, false, false).Reply(); err == nil {
+				o.edid = string(p.Data)
+			}
+			outs = append(outs, o)
+		}
+	}
+	return outs, nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Ranges bound the sliders and every value we apply.
+type Ranges struct {
+	TempMin       int     `json:"temp_min"`
+	TempMax       int     `json:"temp_max"`
+	BrightnessMin float64 `json:"brightness_min"`
+	BrightnessMax float64 `json:"brightness_max"`
+	GammaMin      float64 `json:"gamma_min"`
+	GammaMax      float64 `json:"gamma_max"`
+	BlueMax       float64 `json:"blue_max"` // percent
+}
+
+var defaultRanges = Ranges{
+	TempMin: 1000, TempMax: 10000,
+	BrightnessMin: 0.10, BrightnessMax: 1.00,
+	GammaMin: 0.50, GammaMax: 2.50,
+	BlueMax: 80,
+}
+
+// redshiftLimits is what redshift accepts; a full blue reduction would
+// need an infinite blue gamma, so it stops short of that.
+var redshiftLimits = Ranges{
+	TempMin: 1000, TempMax: 25000,
+	BrightnessMin: 0.10, BrightnessMax: 1.00,
+	GammaMin: 0.10, GammaMax: 10,
+	BlueMax: 95,
+}
+
+// clamp keeps s inside r.
+func (r Ranges) clamp(s Settings) Settings {
+	s.TempK = min(max(s.TempK, r.TempMin), r.TempMax)
+	s.Brightness = math.Min(math.Max(s.Brightness, r.BrightnessMin), r.BrightnessMax)
+	s.Gamma = math.Min(math.Max(s.Gamma, r.GammaMin), r.GammaMax)
+	s.BlueReduction = math.Min(math.Max(s.BlueReduction, 0), r.BlueMax/100)
+	return s
+}
+
+// within narrows r to limits. A bound that ends up past its partner is
+// dropped in favour of the limit.
+func (r Ranges) within(l Ranges) Ranges {
+	n := Ranges{
+		TempMin: max(r.TempMin, l.TempMin), TempMax: min(r.TempMax, l.TempMax),
+		BrightnessMin: math.Max(r.BrightnessMin, l.BrightnessMin), BrightnessMax: math.Min(r.BrightnessMax, l.BrightnessMax),
+		GammaMin: math.Max(r.GammaMin, l.GammaMin), GammaMax: math.Min(r.GammaMax, l.GammaMax),
+		BlueMax: math.Min(math.Max(r.BlueMax, 1), l.BlueMax),
+	}
+	if n.TempMin >= n.TempMax {
+		n.TempMin, n.TempMax = l.TempMin, l.TempMax
+	}
+	if n.BrightnessMin >= n.BrightnessMax {
+		n.BrightnessMin, n.BrightnessMax = l.BrightnessMin, l.BrightnessMax
+	}
+	if n.GammaMin >= n.GammaMax {
+		n.GammaMin, n.GammaMax = l.GammaMin, l.GammaMax
+	}
+	return n
+}
+
+// ranges returns the configured ranges as the backend can honour them.
+// Backends change, so the configured ranges are kept as asked for.
+func (u *uiState) ranges() Ranges {
+	return u.cfg.Ranges.within(backendLimits(u.backendFor(defaultSettings)))
+}
+
+// applyRanges sets the slider ranges from the configuration.
+func (u *uiState) applyRanges() {
+	r := u.ranges()
+	u.limits.Store(&r)
+	u.tempK.SetRange(float64(r.TempMin), float64(r.TempMax))
+	u.brightness.SetRange(r.BrightnessMin, r.BrightnessMax)
+	u.gamma.SetRange(r.GammaMin, r.GammaMax)
+	u.blue.SetRange(0, r.BlueMax)
+}
+
+func (u *uiState) showRanges() {
+	r := u.ranges()
+	vals := []float64{float64(r.TempMin), float64(r.TempMax), r.BrightnessMin, r.BrightnessMax, r.GammaMin, r.GammaMax, r.BlueMax}
+	labels := []string{"Temperature from (K)", "Temperature to (K)", "Brightness from", "Brightness to", "Gamma from", "Gamma to", "Blue reduction up to (%)"}
+	entries := make([]*widget.Entry, len(vals))
+	items := make([]*widget.FormItem, len(vals))
+	for i, v := range vals {
+		entries[i] = widget.NewEntry()
+		entries[i].SetText(formatNum("%g", v))
+		items[i] = widget.NewFormItem(labels[i], entries[i])
+	}
+	dialog.ShowForm("Slider ranges", "Save", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		for i, e := range entries {
+			v, err := parseNum(e.Text)
+			if err != nil {
+				u.out.SetText(fmt.Sprintf("Ranges not saved: %s: %v", labels[i], err))
+				return
+			}
+			vals[i] = v
+		}
+		want := Ranges{int(vals[0]), int(vals[1]), vals[2], vals[3], vals[4], vals[5], vals[6]}
+		u.cfg.Ranges = want
+		u.saveConfig()
+		u.applyRanges()
+		if u.ranges() != want {
+			u.out.SetText(fmt.Sprintf("Slider ranges updated, limited to what %s supports.", u.backendFor(defaultSettings).Name()))
+		} else {
+			u.out.SetText("Slider ranges updated.")
+		}
+	}, u.win)
+}
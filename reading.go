@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+const readingOverride = "Reading"
+
+// startReading applies the Reading preset for the configured session
+// length, after which the previous settings come back on their own.
+// Calling it during a session restarts the countdown.
+func (u *uiState) startReading() {
+	d := time.Duration(u.cfg.ReadingMinutes) * time.Minute
+	u.pushTimedOverride(readingOverride, u.cfg.ReadingPreset, d)
+	u.out.SetText(fmt.Sprintf("Reading for %d min.", u.cfg.ReadingMinutes))
+}
+
+// useForReading stores the current sliders as the Reading preset.
+func (u *uiState) useForReading() {
+	u.cfg.ReadingPreset = u.current()
+	u.saveConfig()
+	u.syncProfiles()
+	u.out.SetText("Reading preset updated.")
+}
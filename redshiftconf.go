@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+)
+
+// redshiftImport is what a redshift.conf sets that we have a use for.
+type redshiftImport struct {
+	day, night Settings
+	loc        *Location
+	high, low  *float64 // elevation-high, elevation-low
+	dawn, dusk string   // dawn-time and dusk-time, "HH:MM-HH:MM"
+}
+
+// redshiftConfPaths are where redshift looks for its config, newest
+// location first.
+func redshiftConfPaths() []string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil
+	}
+	return []string{filepath.Join(dir, "redshift", "redshift.conf"), filepath.Join(dir, "redshift.conf")}
+}
+
+// parseRedshiftConf reads the [redshift] and [manual] sections of a
+// redshift.conf. Keys it doesn't know are skipped, as redshift itself
+// would complain about them anyway. Per-channel gamma is averaged.
+func parseRedshiftConf(r io.Reader) (redshiftImport, error) {
+	// redshift's own defaults, for keys the file leaves out
+	c := redshiftImport{
+		day:   Settings{TempK: 6500, Brightness: 1, Gamma: 1},
+		night: Settings{TempK: 4500, Brightness: 1, Gamma: 1},
+	}
+	var lat, lon *float64
+	section := ""
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(strings.Trim(line, "[]"))
+			continue
+		}
+		key, val, ok := strings.Cut(line, "=")
+		if !ok {
+			return c, fmt.Errorf("line %d: no '=' in %q", n, line)
+		}
+		key, val = strings.TrimSpace(strings.ToLower(key)), strings.TrimSpace(val)
+		num := func() (float64, error) {
+			v, err := strconv.ParseFloat(val, 64)
+			if err != nil {
+				return 0, fmt.Errorf("line %d: %s: %q is not a number", n, key, val)
+			}
+			return v, nil
+		}
+		gamma := func() (float64, error) {
+			var sum float64
+			parts := strings.Split(val, ":")
+			for _, p := range parts {
+				v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+				if err != nil || len(parts) != 1 && len(parts) != 3 {
+					return 0, fmt.Errorf("line %d: %s: %q is not a gamma", n, key, val)
+				}
+				sum += v
+			}
+			return sum / float64(len(parts)), nil
+		}
+		var err error
+		var v float64
+		switch section + "." + key {
+		case "redshift.temp-day":
+			v, err = num()
+			c.day.TempK = int(v)
+		case "redshift.temp-night":
+			v, err = num()
+			c.night.TempK = int(v)
+		case "redshift.brightness":
+			v, err = num()
+			c.day.Brightness, c.night.Brightness = v, v
+		case "redshift.brightness-day":
+			c.day.Brightness, err = num()
+		case "redshift.brightness-night":
+			c.night.Brightness, err = num()
+		case "redshift.gamma":
+			v, err = gamma()
+			c.day.Gamma, c.night.Gamma = v, v
+		case "redshift.gamma-day":
+			c.day.Gamma, err = gamma()
+		case "redshift.gamma-night":
+			c.night.Gamma, err = gamma()
+		case "redshift.elevation-high":
+			v, err = num()
+			c.high = &v
+		case "redshift.elevation-low":
+			v, err = num()
+			c.low = &v
+		case "redshift.dawn-time":
+			c.dawn = val
+		case "redshift.dusk-time":
+			c.dusk = val
+		case "manual.lat":
+			v, err = num()
+			lat = &v
+		case "manual.lon":
+			v, err = num()
+			lon = &v
+		}
+		if err != nil {
+			return c, err
+		}
+	}
+	if lat != nil && lon != nil {
+		c.loc = &Location{Lat: *lat, Lon: *lon}
+	}
+	return c, sc.Err()
+}
+
+// offerRedshiftImport looks for redshift's own config and, if there is
+// one, offers to take its day and night values, location and timing as
+// presets, Auto's settings and the schedule.
+func (u *uiState) offerRedshiftImport() {
+	var path string
+	for _, p := range redshiftConfPaths() {
+		if _, err := os.Stat(p); err == nil {
+			path = p
+			break
+		}
+	}
+	if path == "" {
+		u.out.SetText("No redshift.conf found.")
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		u.out.SetText("redshift.conf: " + err.Error())
+		return
+	}
+	c, err := parseRedshiftConf(f)
+	f.Close()
+	if err != nil {
+		u.out.SetText("redshift.conf: " + err.Error())
+		return
+	}
+	r := u.ranges()
+	c.day, c.night = r.clamp(c.day), r.clamp(c.night)
+
+	found := fmt.Sprintf("Day %d K at %.0f%%, night %d K at %.0f%%",
+		c.day.TempK, c.day.Brightness*100, c.night.TempK, c.night.Brightness*100)
+	if c.loc != nil {
+		found += ", near " + c.loc.coarse(u.cfg.LocationPrecision).String()
+	}
+	dialog.ShowConfirm("Import from redshift",
+		found+".\n\nSave these as the Day and Night presets and use them for Auto and the schedule?",
+		func(ok bool) {
+			if ok {
+				u.importRedshift(c)
+			}
+		}, u.win)
+}
+
+func (u *uiState) importRedshift(c redshiftImport) {
+	ps := slices.DeleteFunc(slices.Clone(u.cfg.Presets), func(p Preset) bool { return p.Name == "Day" || p.Name == "Night" })
+	u.setPresets(append(ps, Preset{"Day", c.day}, Preset{"Night", c.night}))
+
+	u.cfg.AutoDay, u.cfg.AutoNight = c.day, c.night
+	if c.high != nil && c.low != nil && *c.high > *c.low {
+		u.cfg.AutoElevationHigh, u.cfg.AutoElevationLow = *c.high, *c.low
+	}
+	dayAt, nightAt := TimeSpec("sunrise"), TimeSpec("sunset")
+	dawnStart, _, dawnOK := strings.Cut(c.dawn, "-")
+	duskStart, _, duskOK := strings.Cut(c.dusk, "-")
+	if dawnOK && duskOK {
+		// Times go to the schedule as given; Auto fades for its own length.
+		dayAt, nightAt = TimeSpec(strings.TrimSpace(dawnStart)), TimeSpec(strings.TrimSpace(duskStart))
+		u.cfg.AutoClock, u.cfg.AutoDayAt, u.cfg.AutoNightAt = true, dayAt, nightAt
+	}
+	u.cfg.ScheduleEntries = []ScheduleEntry{{At: dayAt, Settings: c.day}, {At: nightAt, Settings: c.night}}
+	if c.loc != nil {
+		u.cfg.ManualLocation = true
+		u.setLocation(*c.loc) // saves, and restarts Auto and the schedule
+	} else {
+		u.saveConfig()
+		u.locationChanged()
+	}
+	u.out.SetText("Imported redshift's settings.")
+}
+
+// exportRedshiftConf saves the Auto day and night values and location as
+// a redshift.conf, so plain redshift keeps following them when we aren't
+// running. It starts in redshift's own config directory.
+func (u *uiState) exportRedshiftConf() {
+	text, err := u.redshiftConfText("Written by Screen Dimmer.")
+	if err != nil {
+		u.out.SetText("Export error: " + err.Error())
+		return
+	}
+	d := dialog.NewFileSave(func(wc fyne.URIWriteCloser, err error) {
+		if err != nil || wc == nil {
+			return
+		}
+		defer wc.Close()
+		if _, err := io.WriteString(wc, text); err != nil {
+			u.out.SetText("Export error: " + err.Error())
+			return
+		}
+		u.out.SetText("Exported to " + wc.URI().Path() + "; run redshift to use it.")
+	}, u.win)
+	d.SetFileName("redshift.conf")
+	if paths := redshiftConfPaths(); len(paths) > 0 {
+		if dir, err := storage.ListerForURI(storage.NewFileURI(filepath.Dir(paths[0]))); err == nil {
+			d.SetLocation(dir)
+		}
+	}
+	d.Show()
+}
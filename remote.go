@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Remote is another machine whose screen we drive over SSH, e.g. a media
+// PC. Host is anything ssh accepts, including aliases from ~/.ssh/config.
+type Remote struct {
+	Host    string `json:"host"`
+	Display string `json:"display"` // X display there, ":0" when empty
+}
+
+// sshArgs wraps a command so ssh runs it on r with DISPLAY set. The
+// remote side goes through a shell, so each word is quoted. BatchMode
+// makes a missing key fail fast instead of waiting for a password prompt
+// nobody can see, and a shared master connection keeps slider drags from
+// paying for a new handshake each time.
+func (r Remote) sshArgs(name string, args []string) []string {
+	display := r.Display
+	if display == "" {
+		display = ":0"
+	}
+	words := append([]string{"env", "DISPLAY=" + display, name}, args...)
+	for i, w := range words {
+		words[i] = shellQuote(w)
+	}
+	return []string{
+		"-o", "BatchMode=yes", "-o", "ConnectTimeout=2",
+		"-o", "ControlMaster=auto", "-o", "ControlPersist=60",
+		"-o", "ControlPath=" + filepath.Join(os.TempDir(), "redshift-control-panel-%C"),
+		"--", r.Host, strings.Join(words, " "),
+	}
+}
+
+// validHost reports whether h can be handed to ssh as a host: a leading
+// "-" would be read as an option.
+func validHost(h string) bool {
+	return h != "" && !strings.HasPrefix(h, "-")
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// exec runs a display command locally, or on the remote target if one is
+// set. Safe to call from any goroutine.
+func (u *uiState) exec(ctx context.Context, name string, args ...string) ([]byte, error) {
+	if r := u.remote.Load(); r != nil {
+		name, args = "ssh", r.sshArgs(name, args)
+	}
+	name, args = hostCommand(name, args...)
+	return u.run(ctx, name, args...)
+}
+
+// hostKey names a target in Config.HostSettings: the SSH host, or ""
+// for this machine.
+func hostKey(r *Remote) string {
+	if r == nil {
+		return ""
+	}
+	return r.Host
+}
+
+// setRemote targets r, or this machine when r is nil. Each target keeps
+// its own slider values, so switching back and forth restores them.
+func (u *uiState) setRemote(r *Remote) {
+	if u.cfg.HostSettings == nil {
+		u.cfg.HostSettings = map[string]Settings{}
+	}
+	u.cfg.HostSettings[hostKey(u.cfg.Remote)] = u.current()
+	u.cfg.Remote = r
+	u.saveConfig()
+	u.remote.Store(r)
+	u.syncControls()
+	if s, ok := u.cfg.HostSettings[hostKey(r)]; ok {
+		u.setSliders(s)
+	}
+	u.scheduleApply(u.target())
+	if r != nil {
+		u.out.SetText("Controlling " + r.Host + ".")
+	} else {
+		u.out.SetText("Controlling this machine.")
+	}
+}
+
+// hostsMenu switches between this machine and the saved remote screens.
+func (u *uiState) hostsMenu() *fyne.Menu {
+	local := fyne.NewMenuItem("This machine", func() { u.setRemote(nil) })
+	local.Checked = u.cfg.Remote == nil
+	items := []*fyne.MenuItem{local}
+	for _, r := range u.cfg.Remotes {
+		item := fyne.NewMenuItem(r.Host, func() { u.setRemote(&r) })
+		item.Checked = u.cfg.Remote != nil && *u.cfg.Remote == r
+		items = append(items, item)
+	}
+	items = append(items, fyne.NewMenuItemSeparator(), fyne.NewMenuItem("Add remote screen…", u.showAddRemote))
+	if r := u.cfg.Remote; r != nil {
+		items = append(items, fyne.NewMenuItem("Remove "+r.Host, func() { u.removeRemote(*r) }))
+	}
+	return fyne.NewMenu("Screens", items...)
+}
+
+func (u *uiState) removeRemote(r Remote) {
+	u.cfg.Remotes = slices.DeleteFunc(u.cfg.Remotes, func(q Remote) bool { return q == r })
+	if u.cfg.Remote != nil && *u.cfg.Remote == r {
+		u.setRemote(nil)
+	}
+	delete(u.cfg.HostSettings, r.Host)
+	u.saveConfig()
+}
+
+func (u *uiState) showAddRemote() {
+	host := widget.NewEntry()
+	host.SetPlaceHolder("user@mediapc")
+	display := widget.NewEntry()
+	display.SetPlaceHolder(":0")
+	dialog.ShowForm("Add remote screen", "Add", "Cancel", []*widget.FormItem{
+		widget.NewFormItem("SSH host", host),
+		widget.NewFormItem("Display", display),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+		h := strings.TrimSpace(host.Text)
+		if !validHost(h) {
+			u.out.SetText(fmt.Sprintf("Not a host: %q", h))
+			return
+		}
+		r := Remote{Host: h, Display: strings.TrimSpace(display.Text)}
+		u.cfg.Remotes = slices.DeleteFunc(u.cfg.Remotes, func(q Remote) bool { return q.Host == h })
+		u.cfg.Remotes = append(u.cfg.Remotes, r)
+		u.setRemote(&r)
+	}, u.win)
+}
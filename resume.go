@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"github.com/godbus/dbus/v5"
+)
+
+// resumeLoop reapplies when the machine wakes from suspend: drivers
+// often reset the gamma ramps on the way, and the schedule may have
+// slept through a change. It runs for the life of the app.
+func (u *uiState) resumeLoop() {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return
+	}
+	match := []dbus.MatchOption{
+		dbus.WithMatchObjectPath("/org/freedesktop/login1"),
+		dbus.WithMatchInterface("org.freedesktop.login1.Manager"),
+		dbus.WithMatchMember("PrepareForSleep"),
+	}
+	if err := conn.AddMatchSignal(match...); err != nil {
+		return
+	}
+	signals := make(chan *dbus.Signal, 4)
+	conn.Signal(signals)
+	for sig := range signals {
+		if sig.Name != "org.freedesktop.login1.Manager.PrepareForSleep" || len(sig.Body) == 0 {
+			continue
+		}
+		if sleeping, _ := sig.Body[0].(bool); sleeping {
+			continue
+		}
+		u.ddc.mu.Lock()
+		u.ddc.buses, u.ddc.last = nil, nil // monitors may come back in a different order
+		u.ddc.mu.Unlock()
+		fyne.Do(func() {
+			if u.scheduleStop != nil {
+				u.restartSchedule()
+			}
+			if u.autoStop != nil {
+				u.restartAuto()
+			}
+			if u.daemon.running() {
+				u.restartRedshiftDaemon() // it only sets the ramps when they should change
+			} else {
+				u.scheduleApply(u.target())
+			}
+		})
+	}
+}
@@ -0,0 +1,385 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// scheduleRecheck bounds how long the scheduler sleeps, so suspend and
+// clock changes are noticed. scheduleBlendStep is how often a blending
+// schedule moves the sliders.
+const (
+	scheduleRecheck   = 15 * time.Minute
+	scheduleBlendStep = time.Minute
+)
+
+// TimeSpec is when a schedule entry starts each day: a clock time such as
+// "21:00", or a solar event with an optional offset such as "sunset",
+// "sunset -30m" or "sunrise +1h".
+type TimeSpec string
+
+// ScheduleEntry switches to Settings at At every day; it stays in effect
+// until the next entry, or with Config.ScheduleBlend is a keyframe the
+// sliders glide from towards the next one.
+type ScheduleEntry struct {
+	At       TimeSpec `json:"at"`
+	Settings Settings `json:"settings"`
+}
+
+var defaultSchedule = []ScheduleEntry{
+	{At: "sunrise", Settings: defaultSettings},
+	{At: "sunset -30m", Settings: Settings{TempK: 4500, Brightness: 0.90, Gamma: 1.00}},
+	{At: "23:00", Settings: Settings{TempK: 3400, Brightness: 0.80, Gamma: 1.00}},
+}
+
+var errNoLocation = errors.New("needs a location")
+
+// resolve returns when t falls on the calendar day of day. Solar events
+// need a location; where the sun doesn't rise or set that day, solar
+// entries are skipped with an error.
+func (t TimeSpec) resolve(day time.Time, loc *Location) (time.Time, error) {
+	s := strings.ToLower(strings.Join(strings.Fields(string(t)), ""))
+	s = strings.NewReplacer("−", "-", "min", "m").Replace(s)
+	y, m, d := day.Date()
+
+	for _, event := range []string{"sunrise", "sunset"} {
+		rest, found := strings.CutPrefix(s, event)
+		if !found {
+			continue
+		}
+		var offset time.Duration
+		if rest != "" {
+			var err error
+			if offset, err = time.ParseDuration(rest); err != nil {
+				return time.Time{}, fmt.Errorf("%q: bad offset", t)
+			}
+		}
+		if loc == nil {
+			return time.Time{}, fmt.Errorf("%q %w", t, errNoLocation)
+		}
+		rise, set, ok := sunTimes(day, *loc)
+		if !ok {
+			return time.Time{}, fmt.Errorf("%q: no %s that day", t, event)
+		}
+		if event == "sunrise" {
+			return rise.Add(offset), nil
+		}
+		return set.Add(offset), nil
+	}
+
+	clock, err := time.Parse("15:04", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither HH:MM nor sunrise/sunset", t)
+	}
+	return time.Date(y, m, d, clock.Hour(), clock.Minute(), 0, 0, day.Location()), nil
+}
+
+// scheduledChange is an entry resolved to a point in time.
+type scheduledChange struct {
+	at       time.Time
+	settings Settings
+}
+
+// scheduleDayStart is the hour schedule days start at. A schedule day
+// runs from noon to noon and is named by its evening, so an entry before
+// noon belongs to the night before: "01:00" on Friday's is early Saturday.
+const scheduleDayStart = 12
+
+// weekSchedule is the entries for weekdays and, if different, for the
+// weekend. The weekend is the schedule days of Friday and Saturday, the
+// nights before a day off: from Friday noon to Sunday noon. Friday night
+// and both weekend mornings follow it, Sunday night goes back to the
+// weekday bedtime.
+type weekSchedule struct {
+	weekdays, weekend []ScheduleEntry
+}
+
+// on returns the entries for the schedule day starting at noon on day.
+func (w weekSchedule) on(day time.Time) []ScheduleEntry {
+	if wd := day.Weekday(); (wd == time.Friday || wd == time.Saturday) && len(w.weekend) > 0 {
+		return w.weekend
+	}
+	return w.weekdays
+}
+
+// resolveOn is when e comes due in the schedule day starting on day.
+func (e ScheduleEntry) resolveOn(day time.Time, loc *Location) (time.Time, error) {
+	at, err := e.At.resolve(day, loc)
+	if err == nil && at.Hour() < scheduleDayStart {
+		at, err = e.At.resolve(day.AddDate(0, 0, 1), loc)
+	}
+	return at, err
+}
+
+// scheduleAround resolves entries for the schedule days either side of
+// now and returns the change in effect at now and the next one. Solar
+// times move every day, so this is recomputed rather than cached.
+func scheduleAround(week weekSchedule, loc *Location, now time.Time) (cur, next *scheduledChange, errs []error) {
+	var changes []scheduledChange
+	for _, dd := range []int{-2, -1, 0, 1} {
+		day := now.AddDate(0, 0, dd)
+		for _, e := range week.on(day) {
+			at, err := e.resolveOn(day, loc)
+			if err != nil {
+				if dd == 0 {
+					errs = append(errs, err)
+				}
+				continue
+			}
+			changes = append(changes, scheduledChange{at, e.Settings})
+		}
+	}
+	slices.SortFunc(changes, func(a, b scheduledChange) int { return a.at.Compare(b.at) })
+	for i := range changes {
+		if !changes[i].at.After(now) {
+			cur = &changes[i]
+		} else if next == nil {
+			next = &changes[i]
+		}
+	}
+	return cur, next, errs
+}
+
+// setSchedule starts or stops following the schedule entries.
+func (u *uiState) setSchedule(on bool) {
+	if on == (u.scheduleStop != nil) {
+		return
+	}
+	if on {
+		u.setAuto(false) // both would move the sliders
+		if u.cfg.RedshiftDaemon {
+			u.setRedshiftDaemon(false)
+		}
+	}
+	u.cfg.Schedule = on
+	u.saveConfig()
+	if !on {
+		close(u.scheduleStop)
+		u.scheduleStop = nil
+		u.setUpcoming(upcoming{})
+		return
+	}
+	u.restartSchedule()
+}
+
+// restartSchedule picks up changed entries or a new location.
+func (u *uiState) restartSchedule() {
+	if u.scheduleStop != nil {
+		close(u.scheduleStop)
+	}
+	stop := make(chan struct{})
+	u.scheduleStop = stop
+	var loc *Location
+	if l, ok := u.location(); ok {
+		loc = &l
+	}
+	go u.scheduleLoop(u.week(), loc, u.cfg.ScheduleBlend, stop)
+}
+
+// scheduleBlend returns the mix of cur and next at now.
+func scheduleBlend(cur, next *scheduledChange, now time.Time) Settings {
+	if next == nil {
+		return cur.settings
+	}
+	t := float64(now.Sub(cur.at)) / float64(next.at.Sub(cur.at))
+	return lerp(cur.settings, next.settings, t)
+}
+
+// scheduleLoop moves the sliders whenever a new entry comes into effect,
+// and once at start. In between the user is free to change them, unless
+// blend has them glide between entries.
+func (u *uiState) scheduleLoop(week weekSchedule, loc *Location, blend bool, stop chan struct{}) {
+	var applied time.Time
+	var last Settings
+	for {
+		now := time.Now()
+		cur, next, errs := scheduleAround(week, loc, now)
+		var s Settings
+		if cur != nil {
+			s = cur.settings
+			if blend {
+				s = scheduleBlend(cur, next, now)
+			}
+		}
+		if cur != nil && (!cur.at.Equal(applied) || s != last) {
+			applied, last = cur.at, s
+			fyne.Do(func() {
+				if u.scheduleStop == stop {
+					u.setSliders(s)
+					u.scheduleApply(u.target())
+					u.syncTray()
+				}
+			})
+		}
+		n := scheduleUpcoming(cur, next)
+		fyne.Do(func() {
+			if u.scheduleStop == stop {
+				u.setUpcoming(n)
+			}
+		})
+		if len(errs) > 0 {
+			fyne.Do(func() {
+				if u.scheduleStop == stop {
+					u.out.SetText("Schedule: " + errors.Join(errs...).Error())
+				}
+			})
+		}
+
+		wait := scheduleRecheck
+		if blend {
+			wait = scheduleBlendStep
+		}
+		if next != nil {
+			wait = min(wait, max(time.Until(next.at), time.Second))
+		}
+		select {
+		case <-stop:
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// showScheduleEditor lists the schedule entries for editing, one row
+// each: when, temperature, brightness and gamma. The rest of an entry's
+// values are kept as they were; new rows start from the sliders. The
+// weekend schedule starts as a copy of the weekday one, and saving it
+// empty makes weekends follow weekdays again. At a place with its own
+// schedule, that one is edited.
+func (u *uiState) showScheduleEditor(weekend bool) {
+	type row struct {
+		base                       Settings
+		at, temp, bright, gammaVal *widget.Entry
+	}
+	newRow := func(e ScheduleEntry) *row {
+		r := &row{base: e.Settings,
+			at: widget.NewEntry(), temp: widget.NewEntry(), bright: widget.NewEntry(), gammaVal: widget.NewEntry()}
+		r.at.SetText(string(e.At))
+		r.at.SetPlaceHolder("21:00 or sunset -30m")
+		r.temp.SetText(strconv.Itoa(e.Settings.TempK))
+		r.bright.SetText(formatNum("%.2f", e.Settings.Brightness))
+		r.gammaVal.SetText(formatNum("%.2f", e.Settings.Gamma))
+		return r
+	}
+	parse := func(r *row) (ScheduleEntry, error) {
+		e := ScheduleEntry{At: TimeSpec(strings.TrimSpace(r.at.Text)), Settings: r.base}
+		// Any location will do to check the syntax of solar entries.
+		if _, err := e.At.resolve(time.Now(), &Location{}); err != nil {
+			return e, err
+		}
+		var err error
+		if e.Settings.TempK, err = strconv.Atoi(strings.TrimSpace(r.temp.Text)); err != nil {
+			return e, fmt.Errorf("%q: bad temperature", r.temp.Text)
+		}
+		if e.Settings.Brightness, err = parseNum(r.bright.Text); err != nil {
+			return e, fmt.Errorf("%q: bad brightness", r.bright.Text)
+		}
+		if e.Settings.Gamma, err = parseNum(r.gammaVal.Text); err != nil {
+			return e, fmt.Errorf("%q: bad gamma", r.gammaVal.Text)
+		}
+		return e, nil
+	}
+	weekdays, weekendDays, where := u.scheduleEntries()
+	title, entries := "Schedule", *weekdays
+	if weekend {
+		title = "Weekend schedule"
+		if len(*weekendDays) > 0 {
+			entries = *weekendDays
+		}
+	}
+	if where != "" {
+		title += " at " + where
+	}
+	var rows []*row
+	for _, e := range entries {
+		rows = append(rows, newRow(e))
+	}
+
+	grid := container.NewGridWithColumns(5)
+	var rebuild func()
+	rebuild = func() {
+		grid.Objects = []fyne.CanvasObject{
+			widget.NewLabel("At"), widget.NewLabel("Kelvin"), widget.NewLabel("Brightness"), widget.NewLabel("Gamma"), layout.NewSpacer(),
+		}
+		for _, r := range rows {
+			remove := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+				rows = slices.DeleteFunc(rows, func(q *row) bool { return q == r })
+				rebuild()
+			})
+			grid.Objects = append(grid.Objects, r.at, r.temp, r.bright, r.gammaVal, remove)
+		}
+		grid.Refresh()
+	}
+	rebuild()
+	add := widget.NewButtonWithIcon("Add entry", theme.ContentAddIcon(), func() {
+		rows = append(rows, newRow(ScheduleEntry{At: "12:00", Settings: u.current()}))
+		rebuild()
+	})
+	blend := widget.NewCheck("Blend between entries", nil)
+	blend.Checked = u.cfg.ScheduleBlend
+
+	collect := func() ([]ScheduleEntry, error) {
+		var entries []ScheduleEntry
+		for _, r := range rows {
+			e, err := parse(r)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, e)
+		}
+		return entries, nil
+	}
+	preview := widget.NewButton("Preview", func() {
+		entries, err := collect()
+		if err != nil {
+			u.out.SetText("Can't preview: " + err.Error())
+			return
+		}
+		week := weekSchedule{entries, *weekendDays}
+		if weekend {
+			week = weekSchedule{*weekdays, entries}
+		}
+		u.showSchedulePreview(week, blend.Checked)
+	})
+
+	scroll := container.NewVScroll(grid)
+	scroll.SetMinSize(fyne.NewSize(480, 240))
+	var top fyne.CanvasObject
+	if weekend {
+		top = widget.NewLabel("From Friday noon to Sunday noon; entries before noon are the next morning.")
+	}
+	content := container.NewBorder(top, container.NewHBox(add, preview, layout.NewSpacer(), blend), nil, nil, scroll)
+	dialog.ShowCustomConfirm(title, "Save", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		entries, err := collect()
+		if err != nil {
+			u.out.SetText("Schedule not saved: " + err.Error())
+			return
+		}
+		weekdays, weekendDays, _ = u.scheduleEntries() // Places may have changed meanwhile
+		if weekend {
+			*weekendDays = entries
+		} else {
+			*weekdays = entries
+		}
+		u.cfg.ScheduleBlend = blend.Checked
+		u.saveConfig()
+		if u.scheduleStop != nil {
+			u.restartSchedule()
+		}
+		u.out.SetText(fmt.Sprintf("%s saved with %d entries.", title, len(entries)))
+	}, u.win)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// seatInfo describes the logind seat this session runs on.
+type seatInfo struct {
+	id    string
+	multi bool // more than one seat exists on this machine
+}
+
+// detectSeat asks logind which seat we are on and whether there are
+// others. Without logind everything is treated as a single seat0.
+func detectSeat() seatInfo {
+	s := seatInfo{id: os.Getenv("XDG_SEAT")}
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return s.withDefault()
+	}
+	login := conn.Object("org.freedesktop.login1", "/org/freedesktop/login1")
+
+	if s.id == "" {
+		var session dbus.ObjectPath
+		if login.Call("org.freedesktop.login1.Manager.GetSessionByPID", 0, uint32(os.Getpid())).Store(&session) == nil {
+			v, err := conn.Object("org.freedesktop.login1", session).GetProperty("org.freedesktop.login1.Session.Seat")
+			if seat, ok := v.Value().([]interface{}); err == nil && ok && len(seat) > 0 {
+				s.id, _ = seat[0].(string)
+			}
+		}
+	}
+
+	var seats []struct {
+		ID   string
+		Path dbus.ObjectPath
+	}
+	if login.Call("org.freedesktop.login1.Manager.ListSeats", 0).Store(&seats) == nil {
+		s.multi = len(seats) > 1
+	}
+	return s.withDefault()
+}
+
+func (s seatInfo) withDefault() seatInfo {
+	if s.id == "" {
+		s.id = "seat0"
+	}
+	return s
+}
+
+// foreignEDIDs returns the EDIDs of displays attached to GPUs that udev
+// assigned to another seat. Outputs showing one of these must not be
+// touched; anything unknown is assumed to be ours.
+func (s seatInfo) foreignEDIDs() map[string]bool {
+	if !s.multi {
+		return nil
+	}
+	foreign := map[string]bool{}
+	connectors, _ := filepath.Glob("/sys/class/drm/card*-*")
+	for _, conn := range connectors {
+		card, _, _ := strings.Cut(filepath.Base(conn), "-")
+		if cardSeat(card) == s.id {
+			continue
+		}
+		if edid, err := os.ReadFile(filepath.Join(conn, "edid")); err == nil && len(edid) > 0 {
+			foreign[string(edid)] = true
+		}
+	}
+	return foreign
+}
+
+// cardSeat reads the ID_SEAT udev property of a DRM card, e.g. "card1".
+func cardSeat(card string) string {
+	dev, err := os.ReadFile(filepath.Join("/sys/class/drm", card, "dev"))
+	if err != nil {
+		return "seat0"
+	}
+	data, err := os.ReadFile("/run/udev/data/c" + strings.TrimSpace(string(dev)))
+	if err != nil {
+		return "seat0"
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if seat, ok := strings.CutPrefix(line, "E:ID_SEAT="); ok {
+			return seat
+		}
+	}
+	return "seat0"
+}
@@ -0,0 +1,83 @@
+package main
+
+import "math"
+
+// Settings is one complete set of display adjustments.
+type Settings struct {
+	TempK         int     `json:"temp_k"`
+	Brightness    float64 `json:"brightness"`
+	Gamma         float64 `json:"gamma"`
+	BlueReduction float64 `json:"blue_reduction"` // 0 leaves blue untouched, 0.8 removes 80% of it
+	Tint          float64 `json:"tint,omitempty"` // Duv off the blackbody locus, + green, - magenta
+
+	// WhiteX and WhiteY set the white point as CIE 1931 xy chromaticity
+	// instead of TempK, for matching monitors whose white is off the
+	// blackbody locus. Both zero means TempK applies.
+	WhiteX float64 `json:"white_x,omitempty"`
+	WhiteY float64 `json:"white_y,omitempty"`
+
+	// gammaRGB is added to Gamma per channel. Only a monitor's
+	// OutputAdjust sets it, so it is never saved.
+	gammaRGB [3]float64
+}
+
+// rgbGamma returns Gamma for each channel with gammaRGB added.
+func (s Settings) rgbGamma() (r, g, b float64) {
+	at := func(i int) float64 { return math.Max(s.Gamma+s.gammaRGB[i], 0.1) } // redshift rejects gamma below 0.1
+	return at(0), at(1), at(2)
+}
+
+// hasWhiteXY reports whether s sets its white point as chromaticity.
+func (s Settings) hasWhiteXY() bool { return s.WhiteX != 0 || s.WhiteY != 0 }
+
+// whiteXY returns the white point of s as chromaticity, whichever way it
+// was set. Tint only moves a Kelvin white; an xy white is already exact.
+func (s Settings) whiteXY() (x, y float64) {
+	if s.hasWhiteXY() {
+		return s.WhiteX, s.WhiteY
+	}
+	return tintedXY(float64(s.TempK), s.Tint)
+}
+
+// defaultSettings is the neutral state the display is in after a reset.
+var defaultSettings = Settings{
+	TempK:      6500,
+	Brightness: 1.00,
+	Gamma:      1.00,
+}
+
+// channelGamma returns the per-channel R, G, B gamma values for s.
+//
+// redshift can only shape a single channel through its gamma, so the blue
+// reduction is folded into the blue gamma such that mid-grey is attenuated
+// by exactly BlueReduction. Whites stay white, which is what users asking
+// for this over a lower Kelvin value want.
+func (s Settings) channelGamma() (r, g, b float64) {
+	r, g, b = s.rgbGamma()
+	if s.BlueReduction > 0 {
+		b *= math.Log(0.5) / math.Log(0.5*(1-s.BlueReduction))
+	}
+	return r, g, math.Max(b, 0.1) // redshift rejects gamma below 0.1
+}
+
+// lerp blends a towards b by t in [0, 1].
+func lerp(a, b Settings, t float64) Settings {
+	t = math.Min(math.Max(t, 0), 1)
+	mix := func(x, y float64) float64 { return x + (y-x)*t }
+	s := Settings{
+		TempK:         int(math.Round(mix(float64(a.TempK), float64(b.TempK)))),
+		Brightness:    mix(a.Brightness, b.Brightness),
+		Gamma:         mix(a.Gamma, b.Gamma),
+		BlueReduction: mix(a.BlueReduction, b.BlueReduction),
+		Tint:          mix(a.Tint, b.Tint),
+	}
+	if t >= 1 {
+		s.WhiteX, s.WhiteY = b.WhiteX, b.WhiteY // end exactly on b, Kelvin or xy
+	} else if a.hasWhiteXY() || b.hasWhiteXY() {
+		// Blend in xy so a fade between Kelvin and chromaticity stays smooth.
+		ax, ay := a.whiteXY()
+		bx, by := b.whiteXY()
+		s.WhiteX, s.WhiteY = mix(ax, bx), mix(ay, by)
+	}
+	return s
+}
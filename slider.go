@@ -1,7 +1,7 @@
 package main
 
 import (
-	"fmt"
+	"math"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -16,7 +16,7 @@ import (
 type LabeledSlider struct {
 	Label      *widget.Label
 	Slider     *widget.Slider
-	valueLabel *widget.Label
+	valueLabel *scrollLabel
 	minLabel   *widget.Label
 	maxLabel   *widget.Label
 	root       fyne.CanvasObject
@@ -34,7 +34,7 @@ func NewLabeledSlider(title string, min, max, step, initial float64, format, uni
 	}
 	s.Value = initial
 
-	val := widget.NewLabel("")
+	val := newScrollLabel()
 	val.Alignment = fyne.TextAlignTrailing
 
 	minLbl := widget.NewLabel("")
@@ -63,6 +63,8 @@ func NewLabeledSlider(title string, min, max, step, initial float64, format, uni
 	ls.maxLabel.SetText(ls.formatValue(max))
 	ls.updateValueLabel(initial)
 
+	val.onScroll = ls.nudge
+
 	s.OnChanged = func(v float64) {
 		ls.updateValueLabel(v)
 		if ls.onChange != nil {
@@ -88,8 +90,53 @@ func (ls *LabeledSlider) SetValue(v float64) {
 	ls.updateValueLabel(v)
 }
 
+// SetEnabled enables or disables the slider, greying out its labels too.
+func (ls *LabeledSlider) SetEnabled(on bool) {
+	if on {
+		ls.Slider.Enable()
+	} else {
+		ls.Slider.Disable()
+	}
+	imp := widget.MediumImportance
+	if !on {
+		imp = widget.LowImportance
+	}
+	for _, l := range []*widget.Label{ls.Label, ls.minLabel, ls.maxLabel} {
+		l.Importance = imp
+		l.Refresh()
+	}
+}
+
+// SetRange changes the slider bounds, clamping the current value into them.
+func (ls *LabeledSlider) SetRange(min, max float64) {
+	s := ls.Slider
+	s.Min, s.Max = min, max
+	ls.minLabel.SetText(ls.formatValue(min))
+	ls.maxLabel.SetText(ls.formatValue(max))
+	if v := math.Min(math.Max(s.Value, min), max); v != s.Value {
+		ls.SetValue(v)
+	} else {
+		s.Refresh()
+	}
+}
+
+// nudge moves the slider by steps increments, clamped to its range. A
+// disabled slider stays put.
+func (ls *LabeledSlider) nudge(steps int) {
+	s := ls.Slider
+	if s.Disabled() {
+		return
+	}
+	step := s.Step
+	if step == 0 {
+		step = (s.Max - s.Min) / 100
+	}
+	v := math.Round(s.Value/step+float64(steps)) * step
+	s.SetValue(math.Min(math.Max(v, s.Min), s.Max))
+}
+
 func (ls *LabeledSlider) formatValue(v float64) string {
-	text := fmt.Sprintf(ls.format, v)
+	text := formatNum(ls.format, v)
 	if ls.unit != "" {
 		text += " " + ls.unit
 	}
@@ -100,8 +147,32 @@ func (ls *LabeledSlider) updateValueLabel(v float64) {
 	ls.valueLabel.SetText(ls.formatValue(v))
 }
 
+// scrollLabel is a label that turns mouse-wheel movement into steps, so
+// the value readout doubles as a fine adjustment control.
+type scrollLabel struct {
+	widget.Label
+	onScroll func(steps int)
+}
+
+func newScrollLabel() *scrollLabel {
+	l := &scrollLabel{}
+	l.ExtendBaseWidget(l)
+	return l
+}
+
+func (l *scrollLabel) Scrolled(ev *fyne.ScrollEvent) {
+	if l.onScroll == nil || ev.Scrolled.DY == 0 {
+		return
+	}
+	if ev.Scrolled.DY > 0 {
+		l.onScroll(1)
+	} else {
+		l.onScroll(-1)
+	}
+}
+
 func fixedSpacer(w float32) fyne.CanvasObject {
-    r := canvas.NewRectangle(color.NRGBA{0, 0, 0, 0}) // transparent
-    r.SetMinSize(fyne.NewSize(w, 0))
-    return r
-}
\ No newline at end of file
+	r := canvas.NewRectangle(color.NRGBA{0, 0, 0, 0}) // transparent
+	r.SetMinSize(fyne.NewSize(w, 0))
+	return r
+}
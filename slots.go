@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/jezek/xgb/xproto"
+)
+
+// slotCount is the number of hotkey slots, bound to Super+Alt+1..9.
+const slotCount = 9
+
+// slotMods is Super+Alt.
+const slotMods = xproto.ModMask4 | xproto.ModMask1
+
+// Preset is a named set of slider values.
+type Preset struct {
+	Name     string   `json:"name"`
+	Settings Settings `json:"settings"`
+}
+
+// applyPreset fades the sliders to p, ending any override.
+func (u *uiState) applyPreset(p Preset) {
+	u.clearOverrides()
+	u.fadeTo(p.Settings)
+	u.out.SetText("Applied " + p.Name + ".")
+}
+
+// presets returns the user's saved presets in the order saved.
+func (u *uiState) presets() []Preset {
+	return slices.Clone(u.cfg.Presets)
+}
+
+// presetNamed looks a saved preset up by name.
+func (u *uiState) presetNamed(name string) (Preset, bool) {
+	i := slices.IndexFunc(u.cfg.Presets, func(p Preset) bool { return p.Name == name })
+	if i < 0 {
+		return Preset{}, false
+	}
+	return u.cfg.Presets[i], true
+}
+
+// applySlot applies the preset in slot i as it is saved now.
+func (u *uiState) applySlot(i int) {
+	if p, ok := u.presetNamed(u.cfg.Slots[i]); ok {
+		u.applyPreset(p)
+	}
+}
+
+// activePreset names the preset the sliders are on, or "" if none is
+// applied or an override is showing something else.
+func (u *uiState) activePreset() string {
+	if len(u.overrides) > 0 {
+		return ""
+	}
+	cur := u.current()
+	for _, p := range u.presets() {
+		if p.Settings == cur {
+			return p.Name
+		}
+	}
+	return ""
+}
+
+func (u *uiState) slotHotkeys() []hotkey {
+	var keys []hotkey
+	for i, name := range u.cfg.Slots {
+		if name == "" {
+			continue
+		}
+		keys = append(keys, hotkey{
+			mods:   slotMods,
+			keysym: xproto.Keysym('1' + i),
+			fire:   func() { u.applySlot(i) },
+		})
+	}
+	return keys
+}
+
+// slotsMenu lists the nine slots, each with apply/assign/save/clear
+// actions.
+func (u *uiState) slotsMenu() *fyne.Menu {
+	items := make([]*fyne.MenuItem, slotCount)
+	for i := range items {
+		name := u.cfg.Slots[i]
+		label := fmt.Sprintf("%d  (empty)", i+1)
+		var assign []*fyne.MenuItem
+		for _, p := range u.cfg.Presets {
+			item := fyne.NewMenuItem(p.Name, func() { u.setSlot(i, p.Name) })
+			item.Checked = p.Name == name
+			assign = append(assign, item)
+		}
+		use := fyne.NewMenuItem("Use preset", nil)
+		use.ChildMenu = fyne.NewMenu("", assign...)
+		use.Disabled = len(assign) == 0
+		sub := []*fyne.MenuItem{use, fyne.NewMenuItem("Save current values here…", func() { u.showSaveSlot(i) })}
+		if name != "" {
+			label = fmt.Sprintf("%d  %s", i+1, name)
+			sub = append([]*fyne.MenuItem{fyne.NewMenuItem("Apply", func() { u.applySlot(i) })},
+				append(sub, fyne.NewMenuItem("Clear", func() { u.setSlot(i, "") }))...)
+		}
+		items[i] = fyne.NewMenuItem(label, nil)
+		items[i].ChildMenu = fyne.NewMenu("", sub...)
+	}
+	return fyne.NewMenu("Hotkey slots", items...)
+}
+
+func (u *uiState) showSaveSlot(i int) {
+	name := widget.NewEntry()
+	name.SetText(fmt.Sprintf("Slot %d", i+1))
+	if n := u.cfg.Slots[i]; n != "" {
+		name.SetText(n)
+	}
+	dialog.ShowForm(fmt.Sprintf("Save to slot %d (Super+Alt+%d)", i+1, i+1), "Save", "Cancel",
+		[]*widget.FormItem{widget.NewFormItem("Name", name)},
+		func(ok bool) {
+			n := strings.TrimSpace(name.Text)
+			if !ok || n == "" {
+				return
+			}
+			u.savePreset(Preset{Name: n, Settings: u.current()})
+			u.setSlot(i, n)
+		}, u.win)
+}
+
+// setSlot puts the preset called name on slot i, or clears it for "".
+func (u *uiState) setSlot(i int, name string) {
+	u.cfg.Slots[i] = name
+	u.saveConfig()
+	u.syncHotkeys()
+	u.syncTray()
+	if name != "" {
+		u.out.SetText(fmt.Sprintf("Saved %s to Super+Alt+%d.", name, i+1))
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// SliderSteps are the increments each slider moves in, by dragging,
+// scrolling over its value or a hotkey.
+type SliderSteps struct {
+	TempK         float64 `json:"temp_k"`
+	Brightness    float64 `json:"brightness"`
+	Gamma         float64 `json:"gamma"`
+	BlueReduction float64 `json:"blue_reduction"` // percent
+}
+
+var defaultSliderSteps = SliderSteps{TempK: 100, Brightness: 0.01, Gamma: 0.01, BlueReduction: 1}
+
+// applySteps sets the configured steps on the sliders, leaving any that
+// are unset or don't fit the slider's range at their current step.
+func (u *uiState) applySteps() {
+	for _, x := range []struct {
+		ls   *LabeledSlider
+		step float64
+	}{
+		{u.tempK, u.cfg.Steps.TempK},
+		{u.brightness, u.cfg.Steps.Brightness},
+		{u.gamma, u.cfg.Steps.Gamma},
+		{u.blue, u.cfg.Steps.BlueReduction},
+	} {
+		if x.step > 0 && x.step <= x.ls.Slider.Max-x.ls.Slider.Min {
+			x.ls.Slider.Step = x.step
+		}
+	}
+}
+
+// stepsPer returns how many steps of ls come closest to amount, at least one.
+func stepsPer(ls *LabeledSlider, amount float64) int {
+	return max(1, int(math.Round(amount/ls.Slider.Step)))
+}
+
+func (u *uiState) showSteps() {
+	entries := []*widget.Entry{widget.NewEntry(), widget.NewEntry(), widget.NewEntry(), widget.NewEntry()}
+	for i, v := range []float64{u.tempK.Slider.Step, u.brightness.Slider.Step, u.gamma.Slider.Step, u.blue.Slider.Step} {
+		entries[i].SetText(formatNum("%g", v))
+	}
+	dialog.ShowForm("Slider steps", "Save", "Cancel", []*widget.FormItem{
+		widget.NewFormItem("Temperature (K)", entries[0]),
+		widget.NewFormItem("Brightness", entries[1]),
+		widget.NewFormItem("Gamma", entries[2]),
+		widget.NewFormItem("Blue reduction (%)", entries[3]),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+		var vals [4]float64
+		for i, e := range entries {
+			v, err := parseNum(e.Text)
+			if err == nil && v <= 0 {
+				err = errors.New("must be positive")
+			}
+			if err != nil {
+				u.out.SetText(fmt.Sprintf("Steps not saved: %q %v", e.Text, err))
+				return
+			}
+			vals[i] = v
+		}
+		u.cfg.Steps = SliderSteps{TempK: vals[0], Brightness: vals[1], Gamma: vals[2], BlueReduction: vals[3]}
+		u.saveConfig()
+		u.applySteps()
+		u.out.SetText("Slider steps updated.")
+	}, u.win)
+}
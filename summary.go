@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+const (
+	summaryEvery       = 7 * 24 * time.Hour
+	summaryCheckEvery  = time.Hour
+	eveningStartHour   = 18 // evening samples are those from 18:00 to midnight
+	summaryTopOverride = 3
+)
+
+// weeklySummary condenses a week of history into one notification body.
+// ok is false when there is nothing worth reporting.
+func weeklySummary(entries []historyEntry) (body string, ok bool) {
+	var tempSum, evening, pauses int
+	var paused time.Duration
+	counts := map[string]int{}
+	for _, e := range entries {
+		switch {
+		case e.Event == eventPause:
+			pauses++
+			paused += time.Duration(e.Seconds) * time.Second
+		case e.Event == eventOverride:
+			counts[e.Mode]++
+		case e.Event == "" && e.Time.Local().Hour() >= eveningStartHour:
+			tempSum += e.Settings.TempK
+			evening++
+		}
+	}
+	if evening == 0 && len(counts) == 0 && pauses == 0 {
+		return "", false
+	}
+
+	var lines []string
+	if evening > 0 {
+		lines = append(lines, fmt.Sprintf("Average evening temperature: %dK.", tempSum/evening))
+	}
+	total := 0
+	names := make([]string, 0, len(counts))
+	for name, n := range counts {
+		total += n
+		names = append(names, name)
+	}
+	if total > 0 {
+		sort.Slice(names, func(i, j int) bool { return counts[names[i]] > counts[names[j]] })
+		if len(names) > summaryTopOverride {
+			names = names[:summaryTopOverride]
+		}
+		for i, name := range names {
+			names[i] = fmt.Sprintf("%s ×%d", name, counts[name])
+		}
+		lines = append(lines, fmt.Sprintf("Overrides taken: %d (%s).", total, strings.Join(names, ", ")))
+	}
+	if pauses > 0 {
+		m := int(paused.Round(time.Minute).Minutes())
+		lines = append(lines, fmt.Sprintf("Schedule paused: %d times, %dh %02dm in all.", pauses, m/60, m%60))
+	}
+	return strings.Join(lines, "\n"), true
+}
+
+// setSummary turns the weekly notification on or off. The summary is
+// built from the usage history, so enabling it also starts recording; the
+// first one arrives a week later.
+func (u *uiState) setSummary(on bool) {
+	if on == (u.summaryStop != nil) {
+		return
+	}
+	if on {
+		u.setHistory(true)
+	}
+	u.cfg.WeeklySummary = on
+	if on && u.cfg.LastSummary.IsZero() {
+		u.cfg.LastSummary = time.Now()
+	}
+	u.saveConfig()
+
+	if !on {
+		close(u.summaryStop)
+		u.summaryStop = nil
+		return
+	}
+	stop := make(chan struct{})
+	u.summaryStop = stop
+	go func() {
+		tick := time.NewTicker(summaryCheckEvery)
+		defer tick.Stop()
+		for {
+			fyne.Do(func() {
+				if u.summaryStop == stop {
+					u.maybeSendSummary()
+				}
+			})
+			select {
+			case <-stop:
+				return
+			case <-tick.C:
+			}
+		}
+	}()
+}
+
+func (u *uiState) maybeSendSummary() {
+	if time.Since(u.cfg.LastSummary) < summaryEvery {
+		return
+	}
+	since := u.cfg.LastSummary
+	u.cfg.LastSummary = time.Now()
+	u.saveConfig()
+
+	go func() {
+		entries, err := readHistory(since)
+		if err != nil {
+			return
+		}
+		if body, ok := weeklySummary(entries); ok {
+			fyne.CurrentApp().SendNotification(fyne.NewNotification("Your week on screen", body))
+		}
+	}()
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// sunTimes returns sunrise and sunset at l on the calendar day of date,
+// using the sunrise equation with the usual -0.833° correction for
+// refraction and the solar disc. ok is false during polar day or night.
+func sunTimes(date time.Time, l Location) (rise, set time.Time, ok bool) {
+	const (
+		j2000  = 2451545.0
+		unixJD = 2440587.5
+	)
+	rad := math.Pi / 180
+	y, m, d := date.Date()
+	noon := time.Date(y, m, d, 12, 0, 0, 0, time.UTC)
+	jd := float64(noon.Unix())/86400 + unixJD
+
+	n := math.Round(jd - j2000 + 0.0008)
+	jStar := n - l.Lon/360
+	M := math.Mod(357.5291+0.98560028*jStar, 360)
+	C := 1.9148*math.Sin(M*rad) + 0.0200*math.Sin(2*M*rad) + 0.0003*math.Sin(3*M*rad)
+	lambda := math.Mod(M+C+180+102.9372, 360)
+	transit := j2000 + jStar + 0.0053*math.Sin(M*rad) - 0.0069*math.Sin(2*lambda*rad)
+
+	sinDecl := math.Sin(lambda*rad) * math.Sin(23.4397*rad)
+	cosDecl := math.Cos(math.Asin(sinDecl))
+	cosHour := (math.Sin(-0.833*rad) - math.Sin(l.Lat*rad)*sinDecl) / (math.Cos(l.Lat*rad) * cosDecl)
+	if cosHour < -1 || cosHour > 1 {
+		return time.Time{}, time.Time{}, false
+	}
+	hour := math.Acos(cosHour) / rad / 360
+
+	toTime := func(j float64) time.Time {
+		return time.Unix(0, int64((j-unixJD)*86400*1e9)).In(date.Location())
+	}
+	return toTime(transit - hour), toTime(transit + hour), true
+}
+
+// sunElevation returns the sun's height above the horizon at l at t, in
+// degrees, from the low-precision formulas of the Astronomical Almanac;
+// they are good to about a hundredth of a degree, far more than fading a
+// screen needs.
+func sunElevation(t time.Time, l Location) float64 {
+	rad := math.Pi / 180
+	d := float64(t.Unix())/86400 - 10957.5 // days since J2000.0
+
+	g := (357.529 + 0.98560028*d) * rad
+	q := 280.459 + 0.98564736*d
+	lambda := (q + 1.915*math.Sin(g) + 0.020*math.Sin(2*g)) * rad
+	e := (23.439 - 0.00000036*d) * rad
+	ra := math.Atan2(math.Cos(e)*math.Sin(lambda), math.Cos(lambda))
+	decl := math.Asin(math.Sin(e) * math.Sin(lambda))
+
+	gmst := math.Mod(18.697374558+24.06570982441908*d, 24)
+	hour := gmst*15*rad + l.Lon*rad - ra
+	lat := l.Lat * rad
+	return math.Asin(math.Sin(lat)*math.Sin(decl)+math.Cos(lat)*math.Cos(decl)*math.Cos(hour)) / rad
+}
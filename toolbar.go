@@ -0,0 +1,150 @@
+package main
+
+import (
+	"slices"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// toolbarAction is something that can be placed in the header bar.
+type toolbarAction struct {
+	id    string // stored in Config.Toolbar
+	label string // as listed in the Toolbar menu
+	make  func(u *uiState) fyne.CanvasObject
+}
+
+// toolbarActions are the header buttons on offer, in display order.
+var toolbarActions = []toolbarAction{
+	{"reset", "Reset", func(u *uiState) fyne.CanvasObject { return u.resetBtn }},
+	{"chips", "Preset buttons", func(u *uiState) fyne.CanvasObject {
+		u.chips = container.NewHBox()
+		u.syncChips()
+		return u.chips
+	}},
+	{"gaming", "Gaming", func(u *uiState) fyne.CanvasObject { return u.gamingBtn }},
+	{"reading", "Reading", func(u *uiState) fyne.CanvasObject {
+		return widget.NewButtonWithIcon("Reading", theme.DocumentIcon(), u.startReading)
+	}},
+	{"pause", "Pause", func(u *uiState) fyne.CanvasObject {
+		var b *widget.Button
+		b = widget.NewButtonWithIcon("Pause", theme.MediaPauseIcon(), func() { u.showMenu(b, u.pauseMenu()) })
+		return b
+	}},
+	{"profile", "Profile dropdown", func(u *uiState) fyne.CanvasObject {
+		names := make([]string, len(activityProfiles))
+		for i, p := range activityProfiles {
+			names[i] = p.name
+		}
+		u.profileSelect = widget.NewSelect(names, func(name string) {
+			if i := slices.IndexFunc(activityProfiles, func(p activityProfile) bool { return p.name == name }); i >= 0 {
+				u.applyProfile(activityProfiles[i])
+			}
+		})
+		u.profileSelect.PlaceHolder = "Profile"
+		u.syncProfiles()
+		return u.profileSelect
+	}},
+	{"compact", "Compact toggle", func(u *uiState) fyne.CanvasObject {
+		var b *widget.Button
+		b = widget.NewButtonWithIcon("", theme.ViewRestoreIcon(), func() {
+			u.setCompact(!u.cfg.Compact)
+			b.Importance = compactImportance(u.cfg.Compact)
+			b.Refresh()
+		})
+		b.Importance = compactImportance(u.cfg.Compact)
+		return b
+	}},
+	{"presets", "Presets", func(u *uiState) fyne.CanvasObject {
+		var b *widget.Button
+		b = widget.NewButtonWithIcon("Presets", theme.MenuDropDownIcon(), func() { u.showMenu(b, u.presetsMenu()) })
+		return b
+	}},
+	{"hosts", "Screen switcher", func(u *uiState) fyne.CanvasObject {
+		var b *widget.Button
+		b = widget.NewButtonWithIcon("Screens", theme.ComputerIcon(), func() { u.showMenu(b, u.hostsMenu()) })
+		return b
+	}},
+	{"quick", "Quick actions", func(u *uiState) fyne.CanvasObject {
+		var b *widget.Button
+		b = widget.NewButtonWithIcon("Quick", theme.MenuDropDownIcon(), func() { u.showMenu(b, u.quickMenu()) })
+		return b
+	}},
+}
+
+var defaultToolbar = []string{"reset", "chips", "gaming", "presets"}
+
+// buildToolbar fills the header with the configured actions; the menu
+// button always stays on the right so the toolbar can't be emptied for good.
+func (u *uiState) buildToolbar() {
+	u.chips, u.profileSelect = nil, nil
+	var objs []fyne.CanvasObject
+	for _, a := range toolbarActions {
+		if slices.Contains(u.cfg.Toolbar, a.id) {
+			objs = append(objs, a.make(u))
+		}
+	}
+	u.header.Objects = append(objs, layout.NewSpacer(), u.menuBtn)
+	u.header.Refresh()
+}
+
+func (u *uiState) toggleToolbar(id string) {
+	if i := slices.Index(u.cfg.Toolbar, id); i >= 0 {
+		u.cfg.Toolbar = slices.Delete(slices.Clone(u.cfg.Toolbar), i, i+1)
+	} else {
+		u.cfg.Toolbar = append(slices.Clone(u.cfg.Toolbar), id)
+	}
+	u.saveConfig()
+	u.buildToolbar()
+}
+
+// toolbarMenu lists the actions with the shown ones checked.
+func (u *uiState) toolbarMenu() *fyne.Menu {
+	items := make([]*fyne.MenuItem, len(toolbarActions))
+	for i, a := range toolbarActions {
+		items[i] = fyne.NewMenuItem(a.label, func() { u.toggleToolbar(a.id) })
+		items[i].Checked = slices.Contains(u.cfg.Toolbar, a.id)
+	}
+	return fyne.NewMenu("Toolbar", items...)
+}
+
+// syncChips fills the preset buttons, if shown, with one small button per
+// preset; the active one stands out.
+func (u *uiState) syncChips() {
+	if u.chips == nil {
+		return
+	}
+	active := u.activePreset()
+	u.chips.Objects = nil
+	for _, p := range u.presets() {
+		b := widget.NewButton(p.Name, func() { u.applyPreset(p) })
+		if p.Name == active {
+			b.Importance = widget.HighImportance
+		}
+		u.chips.Objects = append(u.chips.Objects, b)
+	}
+	u.chips.Refresh()
+}
+
+// setCompact hides or shows the sliders and fits the window to what is
+// left.
+func (u *uiState) setCompact(on bool) {
+	u.cfg.Compact = on
+	u.saveConfig()
+	if on {
+		u.panel.Hide()
+		u.win.Resize(u.win.Content().MinSize())
+	} else {
+		u.panel.Show()
+	}
+}
+
+func compactImportance(on bool) widget.Importance {
+	if on {
+		return widget.HighImportance
+	}
+	return widget.MediumImportance
+}
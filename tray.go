@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+)
+
+// syncTray rebuilds the system tray menu so its check marks match the
+// current state, along with the header's preset buttons. The tray part
+// does nothing where the driver has no tray.
+func (u *uiState) syncTray() {
+	u.syncChips()
+	u.syncProfiles()
+	desk, ok := fyne.CurrentApp().(desktop.App)
+	if !ok {
+		return
+	}
+	u.trayPreset = u.activePreset()
+	presets := fyne.NewMenuItem("Presets", nil)
+	presets.ChildMenu = u.presetsMenu()
+	quick := fyne.NewMenuItem("Quick actions", nil)
+	quick.ChildMenu = u.quickMenu()
+	pause := fyne.NewMenuItem("Pause", nil)
+	pause.ChildMenu = u.pauseMenu()
+	desk.SetSystemTrayMenu(fyne.NewMenu("Screen Dimmer",
+		fyne.NewMenuItem("Show", u.win.Show),
+		fyne.NewMenuItemSeparator(),
+		presets,
+		quick,
+		pause,
+		fyne.NewMenuItem("Reset to defaults", func() { go u.reset() }),
+	))
+}
+
+// presetsMenu lists the saved presets with the active one checked.
+func (u *uiState) presetsMenu() *fyne.Menu {
+	active := u.activePreset()
+	var items []*fyne.MenuItem
+	for _, p := range u.presets() {
+		item := fyne.NewMenuItem(p.Name, func() { u.applyPreset(p) })
+		item.Checked = p.Name == active
+		items = append(items, item)
+	}
+	if len(items) == 0 {
+		none := fyne.NewMenuItem("No presets saved", nil)
+		none.Disabled = true
+		items = append(items, none)
+	}
+	items = append(items, fyne.NewMenuItemSeparator(), fyne.NewMenuItem("Save current as preset…", u.showSavePreset))
+	if len(u.cfg.Presets) > 0 {
+		var del []*fyne.MenuItem
+		for _, p := range u.cfg.Presets {
+			del = append(del, fyne.NewMenuItem(p.Name, func() { u.deletePreset(p.Name) }))
+		}
+		remove := fyne.NewMenuItem("Delete preset", nil)
+		remove.ChildMenu = fyne.NewMenu("", del...)
+		items = append(items, remove)
+	}
+	items = append(items,
+		fyne.NewMenuItem("Import presets…", u.importPresets),
+		fyne.NewMenuItem("Export presets…", u.exportPresets))
+	return fyne.NewMenu("Presets", items...)
+}
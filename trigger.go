@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+const (
+	triggerPollInterval = 2 * time.Second
+	triggerValuesFile   = "apply" // holds key=value pairs rather than naming a preset
+)
+
+// triggerDir returns the watched directory, by default
+// $XDG_CONFIG_HOME/redshift-control-panel/triggers.
+func (u *uiState) triggerDir() (string, error) {
+	if u.cfg.TriggerDir != "" {
+		return u.cfg.TriggerDir, nil
+	}
+	path, err := configPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "triggers"), nil
+}
+
+// setFileTriggers starts or stops watching the trigger directory. Touching
+// a file there named after a preset applies it; writing "temp=4000
+// brightness=0.8" into a file named apply sets those values. It's meant
+// for shell scripts and remote tools that can't speak D-Bus.
+func (u *uiState) setFileTriggers(on bool) {
+	if on == (u.triggerStop != nil) {
+		return
+	}
+	u.cfg.FileTriggers = on
+	u.saveConfig()
+	if !on {
+		close(u.triggerStop)
+		u.triggerStop = nil
+		return
+	}
+	dir, err := u.triggerDir()
+	if err == nil {
+		err = os.MkdirAll(dir, 0o755)
+	}
+	if err != nil {
+		u.out.SetText("File triggers: " + err.Error())
+		return
+	}
+	stop := make(chan struct{})
+	u.triggerStop = stop
+	go u.triggerLoop(dir, stop)
+	u.out.SetText("Watching " + dir)
+}
+
+// triggerLoop polls dir and fires each file whose modification time moved
+// since the last look. Files already there at start don't fire.
+func (u *uiState) triggerLoop(dir string, stop chan struct{}) {
+	tick := time.NewTicker(triggerPollInterval)
+	defer tick.Stop()
+	seen := triggerTimes(dir)
+	for {
+		select {
+		case <-stop:
+			return
+		case <-tick.C:
+		}
+		now := triggerTimes(dir)
+		for name, mod := range now {
+			if !mod.After(seen[name]) {
+				continue
+			}
+			data, _ := os.ReadFile(filepath.Join(dir, name))
+			fyne.Do(func() {
+				if u.triggerStop == stop {
+					u.fireTrigger(name, string(data))
+				}
+			})
+		}
+		seen = now
+	}
+}
+
+func triggerTimes(dir string) map[string]time.Time {
+	times := map[string]time.Time{}
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if info, err := e.Info(); err == nil && info.Mode().IsRegular() {
+			times[e.Name()] = info.ModTime()
+		}
+	}
+	return times
+}
+
+func (u *uiState) fireTrigger(name, content string) {
+	if name == triggerValuesFile {
+		s, err := parseTriggerValues(u.current(), content)
+		if err != nil {
+			u.out.SetText("Trigger " + name + ": " + err.Error())
+			return
+		}
+		s = u.ranges().clamp(s)
+		u.applyPreset(Preset{Name: "trigger values", Settings: s})
+		return
+	}
+	for _, p := range u.presets() {
+		if strings.EqualFold(p.Name, name) {
+			u.applyPreset(p)
+			return
+		}
+	}
+	u.out.SetText("Trigger " + name + ": no such preset")
+}
+
+// parseTriggerValues applies whitespace-separated key=value pairs to s,
+// unclamped.
+// Keys are temp, brightness, gamma and blue (percent, like the slider).
+func parseTriggerValues(s Settings, content string) (Settings, error) {
+	for _, field := range strings.Fields(content) {
+		key, val, ok := strings.Cut(field, "=")
+		if !ok {
+			return s, fmt.Errorf("%q is not key=value", field)
+		}
+		v, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return s, fmt.Errorf("%s: %v", key, err)
+		}
+		switch strings.ToLower(key) {
+		case "temp", "temp_k":
+			s.TempK = int(v)
+		case "brightness":
+			s.Brightness = v
+		case "gamma":
+			s.Gamma = v
+		case "blue":
+			s.BlueReduction = v / 100
+		default:
+			return s, fmt.Errorf("unknown key %q", key)
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// upcomingHorizon is how far ahead Auto looks for its next switch;
+// upcomingStep is how finely.
+const (
+	upcomingHorizon = 48 * time.Hour
+	upcomingStep    = 5 * time.Minute
+)
+
+// upcoming is the next change Auto or the schedule will make, counted
+// down after the overrides in the status bar.
+type upcoming struct {
+	label string // e.g. "Night mode"
+	at    time.Time
+}
+
+func (n upcoming) String() string {
+	left := time.Until(n.at)
+	if n.label == "" || left <= 0 {
+		return ""
+	}
+	m := int((left + time.Minute - 1) / time.Minute) // round up, so "in 0m" never shows
+	if m < 60 {
+		return fmt.Sprintf("%s in %dm", n.label, m)
+	}
+	return fmt.Sprintf("%s in %dh %02dm", n.label, m/60, m%60)
+}
+
+// setUpcoming shows n in the status bar; the zero value clears it.
+func (u *uiState) setUpcoming(n upcoming) {
+	u.upcoming = n
+	u.refreshMode()
+}
+
+// nextSwitch finds when phase next reaches full night, if it is closer to
+// day at now, or full day otherwise.
+func nextSwitch(phase func(time.Time) (float64, error), now time.Time) upcoming {
+	p, _ := phase(now)
+	toNight := p < 0.5
+	for t := now.Add(upcomingStep); t.Before(now.Add(upcomingHorizon)); t = t.Add(upcomingStep) {
+		q, err := phase(t)
+		if err != nil {
+			continue
+		}
+		if toNight && q >= 1 {
+			return upcoming{"Night mode", t}
+		}
+		if !toNight && q <= 0 {
+			return upcoming{"Day mode", t}
+		}
+	}
+	return upcoming{}
+}
+
+// scheduleUpcoming describes the schedule's next change by which way it
+// moves the temperature.
+func scheduleUpcoming(cur, next *scheduledChange) upcoming {
+	if next == nil {
+		return upcoming{}
+	}
+	label := "Next change"
+	if cur != nil && next.settings.TempK < cur.settings.TempK {
+		label = "Warmer"
+	} else if cur != nil && next.settings.TempK > cur.settings.TempK {
+		label = "Cooler"
+	}
+	return upcoming{label, next.at}
+}
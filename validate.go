@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+)
+
+// Warning describes one value validate had to change.
+type Warning struct {
+	Field     string // "temperature", "brightness", "gamma", "blue reduction", ...
+	Requested float64
+	Applied   float64
+	Reason    string // "range", "safety floor", "not a number", ...
+}
+
+func (w Warning) String() string {
+	if w.Reason == "not a number" {
+		return fmt.Sprintf("%s was not a number, used %s", w.Field, formatNum("%g", w.Applied))
+	}
+	return fmt.Sprintf("%s %s → %s (%s)", w.Field, formatNum("%g", w.Requested), formatNum("%g", w.Applied), w.Reason)
+}
+
+// validate is the single gate every requested state passes before it
+// reaches a backend, whether it came from the sliders, a preset, a
+// trigger, IPC or a schedule. It clamps s to the configured ranges, which
+// are already within the backend's limits, and then to the safety floor,
+// and reports each change. Must be called on the UI thread.
+func (u *uiState) validate(s Settings) (Settings, []Warning) {
+	r := u.ranges()
+	var warns []Warning
+	fix := func(field string, v *float64, lo, hi, fallback float64) {
+		switch {
+		case math.IsNaN(*v) || math.IsInf(*v, 0):
+			warns = append(warns, Warning{field, *v, fallback, "not a number"})
+			*v = fallback
+		case *v < lo || *v > hi:
+			c := math.Min(math.Max(*v, lo), hi)
+			warns = append(warns, Warning{field, *v, c, "range"})
+			*v = c
+		}
+	}
+
+	temp := float64(s.TempK)
+	fix("temperature", &temp, float64(r.TempMin), float64(r.TempMax), float64(defaultSettings.TempK))
+	s.TempK = int(temp)
+	fix("brightness", &s.Brightness, r.BrightnessMin, r.BrightnessMax, defaultSettings.Brightness)
+	fix("gamma", &s.Gamma, r.GammaMin, r.GammaMax, defaultSettings.Gamma)
+	blue := s.BlueReduction * 100
+	fix("blue reduction", &blue, 0, r.BlueMax, 0)
+	s.BlueReduction = blue / 100
+	fix("tint", &s.Tint, -tintMax, tintMax, 0)
+	if s.Tint != 0 && u.remote.Load() != nil && !u.backend().Capabilities().WhitePoint {
+		warns = append(warns, Warning{"tint", s.Tint, 0, "needs a local display"})
+		s.Tint = 0
+	}
+
+	if s.hasWhiteXY() {
+		switch {
+		case !validChromaticity(s.WhiteX, s.WhiteY):
+			warns = append(warns, Warning{"white point x", s.WhiteX, 0, "not a chromaticity"})
+			s.WhiteX, s.WhiteY = 0, 0
+		case u.remote.Load() != nil && !u.backend().Capabilities().WhitePoint:
+			warns = append(warns, Warning{"white point x", s.WhiteX, 0, "needs a local display"})
+			s.WhiteX, s.WhiteY = 0, 0
+		}
+	}
+
+	if floor := u.cfg.SafetyFloor; s.Brightness < floor {
+		warns = append(warns, Warning{"brightness", s.Brightness, floor, "safety floor"})
+		s.Brightness = floor
+	}
+	u.logWarnings(warns)
+	return s, warns
+}
+
+// logWarnings logs warns when they concern other fields or reasons than
+// the last call's, so a drag held at a limit logs once, not once a tick.
+func (u *uiState) logWarnings(warns []Warning) {
+	keys := make([]string, len(warns))
+	for i, w := range warns {
+		keys[i] = w.Field + ": " + w.Reason
+	}
+	key := strings.Join(keys, "; ")
+	if key == u.warned {
+		return
+	}
+	u.warned = key
+	for _, w := range warns {
+		log.Printf("validate: %s", w)
+	}
+}
+
+// warningText joins warnings for the status line, "" when there are none.
+func warningText(warns []Warning) string {
+	if len(warns) == 0 {
+		return ""
+	}
+	parts := make([]string, len(warns))
+	for i, w := range warns {
+		parts[i] = w.String()
+	}
+	return " Adjusted: " + strings.Join(parts, "; ") + "."
+}
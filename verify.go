@@ -0,0 +1,104 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+const (
+	verifyInterval = 5 * time.Second
+	verifyExact    = 0.01 // our own ramps: rounding and dithering only
+	verifyLoose    = 0.06 // other tools' white point tables differ from ours
+)
+
+const overriddenText = "Another program has changed the gamma ramps; apply again to restore them."
+
+// rampWriter is implemented by backends whose result ends up in the RandR
+// gamma ramps, where it can be read back and checked.
+type rampWriter interface {
+	WritesRamps() bool
+}
+
+func (b *rampBackend) WritesRamps() bool   { return true }
+func (b *xsctBackend) WritesRamps() bool   { return true }
+func (b *xrandrBackend) WritesRamps() bool { return true }
+
+func (b *redshiftBackend) WritesRamps() bool {
+	m := b.u.pinnedMethod()
+	return m == "" || m == "randr"
+}
+
+func (b *gammastepBackend) WritesRamps() bool {
+	m := b.u.pinnedMethod()
+	return m == "randr" || m == "" && !waylandSession()
+}
+
+// verifyTarget is what the last apply should have left in the ramps.
+type verifyTarget struct {
+	s         Settings
+	crtc      crtcSettings // what each CRTC gets of s
+	base      *Ramp
+	ref       refCurve
+	tolerance float64
+}
+
+// verifyTargetFor returns what applying s with b should leave in the
+// ramps, or nil if b's result can't be read back here.
+func (u *uiState) verifyTargetFor(b Backend, s Settings) *verifyTarget {
+	w, ok := b.(rampWriter)
+	if !ok || !w.WritesRamps() || u.remote.Load() != nil {
+		return nil
+	}
+	if screens, def := u.xScreens(); !slices.Contains(screens, def) {
+		return nil // only the default screen is read back
+	}
+	t := &verifyTarget{s: s, crtc: u.outputSettings(), tolerance: verifyLoose}
+	if b == Backend(u.ramps) {
+		t.base, t.ref, t.tolerance = u.base.Load(), refCurve(u.reference.Load()), verifyExact
+	}
+	return t
+}
+
+// overridden reads the ramps back and reports whether any CRTC holds
+// something other than t. Only mid-grey is compared: the tools shape the
+// rest of the curve differently, but all of them put it in about the
+// same place. A display that can't be read counts as unchanged.
+func (u *uiState) overridden(t *verifyTarget) bool {
+	ramps, err := seatRamps(u.seat.foreignEDIDs())
+	if err != nil {
+		return false
+	}
+	matches := func(got Ramp, s Settings) bool {
+		want := computeRampRef(s, t.base, t.ref, got.Size())
+		for _, c := range [][2][]uint16{{got.R, want.R}, {got.G, want.G}, {got.B, want.B}} {
+			if math.Abs(got.sample(c[0], 0.5)-want.sample(c[1], 0.5)) > t.tolerance {
+				return false
+			}
+		}
+		return true
+	}
+	for crtc, r := range ramps {
+		if !matches(r, t.crtc(crtc, t.s)) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyLoop re-reads the ramps every verifyInterval and says so once per
+// apply when something has replaced ours. It runs for the life of the
+// app.
+func (u *uiState) verifyLoop() {
+	tick := time.NewTicker(verifyInterval)
+	defer tick.Stop()
+	for range tick.C {
+		t := u.verify.Load()
+		if t == nil || !u.overridden(t) || !u.verify.CompareAndSwap(t, nil) {
+			continue
+		}
+		fyne.Do(func() { u.out.SetText(overriddenText) })
+	}
+}
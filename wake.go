@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+const (
+	wakePollInterval = 30 * time.Second
+	sunriseOverride  = "Sunrise"
+)
+
+// setWakeRamp starts or stops the sunrise simulation: over the WakeMinutes
+// before the next alarm the screen ramps from what it shows at night to
+// the WakePreset, so it is already bright when the alarm rings.
+func (u *uiState) setWakeRamp(on bool) {
+	if on == (u.wakeStop != nil) {
+		return
+	}
+	u.cfg.WakeRamp = on
+	u.saveConfig()
+	if !on {
+		close(u.wakeStop)
+		u.wakeStop = nil
+		u.popOverride(sunriseOverride)
+		return
+	}
+	stop := make(chan struct{})
+	u.wakeStop = stop
+	go u.wakeLoop(u.cfg.WakeTime, time.Duration(u.cfg.WakeMinutes)*time.Minute, stop)
+}
+
+func (u *uiState) wakeLoop(fixed string, ramp time.Duration, stop chan struct{}) {
+	tick := time.NewTicker(wakePollInterval)
+	defer tick.Stop()
+	var alarm time.Time // of the ramp in progress, zero when none
+	for {
+		now := time.Now()
+		next, err := nextAlarm(fixed, now)
+		switch {
+		case err == nil && alarm.IsZero() && next.Sub(now) <= ramp:
+			alarm = next
+			fyne.Do(func() {
+				if u.wakeStop == stop {
+					u.wakeFrom = u.target()
+					u.pushOverride(sunriseOverride, u.wakeFrom)
+				}
+			})
+		case !alarm.IsZero() && !now.Before(alarm):
+			alarm = time.Time{}
+			fyne.Do(func() {
+				if u.wakeStop == stop {
+					u.popOverride(sunriseOverride)
+					u.setSliders(u.cfg.WakePreset)
+					u.scheduleApply(u.target())
+				}
+			})
+		}
+		if !alarm.IsZero() {
+			t := 1 - float64(alarm.Sub(now))/float64(ramp)
+			fyne.Do(func() {
+				if u.wakeStop != stop {
+					return
+				}
+				u.updateOverride(sunriseOverride, lerp(u.wakeFrom, u.cfg.WakePreset, t))
+			})
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-tick.C:
+		}
+	}
+}
+
+// nextAlarm returns when the alarm next rings after now: the fixed
+// "HH:MM" wake time if one is configured, otherwise the soonest enabled
+// GNOME Clocks alarm.
+func nextAlarm(fixed string, now time.Time) (time.Time, error) {
+	if fixed != "" {
+		at, err := TimeSpec(fixed).resolve(now, nil)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if !at.After(now) {
+			at = at.AddDate(0, 0, 1)
+		}
+		return at, nil
+	}
+	alarms, err := gnomeClocksAlarms()
+	if err != nil {
+		return time.Time{}, err
+	}
+	var best time.Time
+	for _, a := range alarms {
+		if at := a.next(now); best.IsZero() || at.Before(best) {
+			best = at
+		}
+	}
+	if best.IsZero() {
+		return best, fmt.Errorf("no alarm set")
+	}
+	return best, nil
+}
+
+// clocksAlarm is one GNOME Clocks alarm. days holds Clocks weekday numbers
+// (0 = Monday); an empty set rings once, at the next matching time.
+type clocksAlarm struct {
+	hour, minute int
+	days         []int
+}
+
+func (a clocksAlarm) next(now time.Time) time.Time {
+	y, m, d := now.Date()
+	for i := 0; i < 8; i++ {
+		at := time.Date(y, m, d+i, a.hour, a.minute, 0, 0, now.Location())
+		if !at.After(now) {
+			continue
+		}
+		weekday := (int(at.Weekday()) + 6) % 7
+		if len(a.days) == 0 || slices.Contains(a.days, weekday) {
+			return at
+		}
+	}
+	return time.Time{}
+}
+
+var (
+	clocksAlarmRe = regexp.MustCompile(`\{[^{}]*\}`)
+	clocksFieldRe = regexp.MustCompile(`'(hour|minute|active|days)': <([^>]*)>`)
+)
+
+// gnomeClocksAlarms reads the alarms GNOME Clocks keeps in GSettings. Clocks
+// has no D-Bus API for them, so this parses the GVariant text of
+// org.gnome.clocks alarms.
+func gnomeClocksAlarms() ([]clocksAlarm, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	name, args := hostCommand("gsettings", "get", "org.gnome.clocks", "alarms")
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("GNOME Clocks alarms: %w", err)
+	}
+	var alarms []clocksAlarm
+	for _, dict := range clocksAlarmRe.FindAllString(string(out), -1) {
+		a, active := clocksAlarm{hour: -1}, true
+		for _, f := range clocksFieldRe.FindAllStringSubmatch(dict, -1) {
+			switch f[1] {
+			case "hour":
+				a.hour, _ = strconv.Atoi(f[2])
+			case "minute":
+				a.minute, _ = strconv.Atoi(f[2])
+			case "active":
+				active = f[2] == "true"
+			case "days":
+				for _, n := range strings.FieldsFunc(f[2], func(r rune) bool { return r < '0' || r > '9' }) {
+					day, _ := strconv.Atoi(n)
+					a.days = append(a.days, day)
+				}
+			}
+		}
+		if active && a.hour >= 0 {
+			alarms = append(alarms, a)
+		}
+	}
+	return alarms, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// validChromaticity reports whether x, y is a physically meaningful
+// white: inside the unit triangle, and not so saturated that it falls
+// outside what sRGB primaries can reproduce at all.
+func validChromaticity(x, y float64) bool {
+	if math.IsNaN(x) || math.IsNaN(y) || x <= 0 || y <= 0 || x+y >= 1 {
+		return false
+	}
+	r, g, b := xyRGB(x, y)
+	return r > 0 && g > 0 && b > 0
+}
+
+// setWhite sets the xy white point the sliders stand for; 0, 0 goes back
+// to the temperature slider. The temperature label says which is in use.
+func (u *uiState) setWhite(x, y float64) {
+	u.whiteX, u.whiteY = x, y
+	if x == 0 && y == 0 {
+		u.tempK.Label.SetText("Temperature (K)")
+		return
+	}
+	u.tempK.Label.SetText(fmt.Sprintf("Temperature (K) — white at x %s, y %s", formatNum("%.4f", x), formatNum("%.4f", y)))
+}
+
+// showWhitePoint asks for a white point as CIE 1931 xy chromaticity, for
+// matching a second monitor whose white is off the blackbody locus. It is
+// prefilled with the current white so small corrections are easy.
+func (u *uiState) showWhitePoint() {
+	x, y := u.current().whiteXY()
+	xe, ye := widget.NewEntry(), widget.NewEntry()
+	xe.SetText(formatNum("%.4f", x))
+	ye.SetText(formatNum("%.4f", y))
+	var d dialog.Dialog
+	kelvin := widget.NewButton("Use temperature instead", func() {
+		d.Hide()
+		u.setWhite(0, 0)
+		u.clearOverrides()
+		u.scheduleApply(u.target())
+		u.out.SetText("White point follows the temperature slider.")
+	})
+	d = dialog.NewForm("White point", "Apply", "Cancel", []*widget.FormItem{
+		widget.NewFormItem("x", xe),
+		widget.NewFormItem("y", ye),
+		widget.NewFormItem("", kelvin),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+		x, errX := parseNum(xe.Text)
+		y, errY := parseNum(ye.Text)
+		if errX != nil || errY != nil || !validChromaticity(x, y) {
+			u.out.SetText(fmt.Sprintf("White point not applied: %q, %q is not a displayable chromaticity.", xe.Text, ye.Text))
+			return
+		}
+		if u.remote.Load() != nil && !u.backend().Capabilities().WhitePoint {
+			u.out.SetText("White point not applied: " + u.backend().Name() + " can't set an xy white point on a remote display.")
+			return
+		}
+		u.setWhite(x, y)
+		u.clearOverrides()
+		u.scheduleApply(u.target())
+		u.syncTray()
+	}, u.win)
+	d.Show()
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+// windDownChoices are the Wind down menu's durations, in minutes.
+var windDownChoices = []int{30, 60, 90, 120}
+
+// windDown eases the sliders to the Wind down preset over minutes,
+// starting now, for the hour before bed without a daily schedule. They
+// stay there afterwards; moving one stops the ramp early.
+func (u *uiState) windDown(minutes int) {
+	u.clearOverrides()
+	d := time.Duration(minutes) * time.Minute
+	u.fadeOver(u.cfg.WindDownPreset, d)
+	u.out.SetText(fmt.Sprintf("Winding down until %s.", time.Now().Add(d).Format("15:04")))
+}
+
+// useForWindDown stores the current sliders as where winding down ends.
+func (u *uiState) useForWindDown() {
+	u.cfg.WindDownPreset = u.current()
+	u.saveConfig()
+	u.out.SetText("Wind down preset updated.")
+}
+
+func (u *uiState) windDownMenu() *fyne.Menu {
+	var items []*fyne.MenuItem
+	for _, n := range windDownChoices {
+		items = append(items, fyne.NewMenuItem(fmt.Sprintf("Over %d minutes", n), func() { u.windDown(n) }))
+	}
+	items = append(items, fyne.NewMenuItemSeparator(), fyne.NewMenuItem("Use current values for Wind down", u.useForWindDown))
+	return fyne.NewMenu("Wind down", items...)
+}
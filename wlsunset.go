@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+const backendWlsunset = "wlsunset"
+
+// wlsunsetBackend holds a temperature with wlsunset, for wlroots
+// compositors (Sway, Hyprland) without gammastep. wlsunset only knows a
+// day and a night temperature, so both are set to the target and the day
+// made to last all day; it has no brightness control.
+type wlsunsetBackend struct {
+	u    *uiState
+	proc process
+}
+
+func (b *wlsunsetBackend) Name() string { return backendWlsunset }
+
+func (b *wlsunsetBackend) Capabilities() Capabilities {
+	return Capabilities{Temperature: true, Gamma: true}
+}
+
+func (b *wlsunsetBackend) Available() bool {
+	return hasCommand("wlsunset") && waylandSession()
+}
+
+func (b *wlsunsetBackend) Apply(ctx context.Context, s Settings) error {
+	// -t must be below -T; a kelvin apart is invisible.
+	return b.proc.restart(ctx, "wlsunset",
+		"-T", fmt.Sprint(s.TempK+1), "-t", fmt.Sprint(s.TempK),
+		"-S", "00:00", "-s", "23:59", "-d", "1",
+		"-g", fmt.Sprintf("%.2f", s.Gamma))
+}
+
+// Reset stops wlsunset; the compositor puts the original ramps back.
+func (b *wlsunsetBackend) Reset(context.Context) error {
+	b.proc.stop()
+	return nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// Small EWMH helpers for the features that look at other windows.
+
+func internAtom(X *xgb.Conn, name string) (xproto.Atom, error) {
+	r, err := xproto.InternAtom(X, false, uint16(len(name)), name).Reply()
+	if err != nil {
+		return 0, err
+	}
+	return r.Atom, nil
+}
+
+// windowProperty32 reads a property made of 32-bit items (atoms, windows).
+func windowProperty32(X *xgb.Conn, win xproto.Window, name string) ([]uint32, error) {
+	prop, err := internAtom(X, name)
+	if err != nil {
+		return nil, err
+	}
+	r, err := xproto.GetProperty(X, false, win, prop, xproto.GetPropertyTypeAny, 0, 1024).Reply()
+	if err != nil {
+		return nil, err
+	}
+	if r.Format != 32 {
+		return nil, nil
+	}
+	vals := make([]uint32, 0, len(r.Value)/4)
+	for i := 0; i+4 <= len(r.Value); i += 4 {
+		vals = append(vals, xgb.Get32(r.Value[i:]))
+	}
+	return vals, nil
+}
+
+// activeWindow returns the window the window manager reports as focused.
+func activeWindow(X *xgb.Conn) (xproto.Window, error) {
+	root := xproto.Setup(X).DefaultScreen(X).Root
+	vals, err := windowProperty32(X, root, "_NET_ACTIVE_WINDOW")
+	if err != nil {
+		return 0, err
+	}
+	if len(vals) == 0 || vals[0] == 0 {
+		return 0, errors.New("no active window")
+	}
+	return xproto.Window(vals[0]), nil
+}
+
+// isFullscreen reports whether win has _NET_WM_STATE_FULLSCREEN set.
+func isFullscreen(X *xgb.Conn, win xproto.Window) bool {
+	full, err := internAtom(X, "_NET_WM_STATE_FULLSCREEN")
+	if err != nil {
+		return false
+	}
+	states, _ := windowProperty32(X, win, "_NET_WM_STATE")
+	for _, s := range states {
+		if xproto.Atom(s) == full {
+			return true
+		}
+	}
+	return false
+}
+
+// wmClass returns the lower-cased instance and class names of win joined by
+// a space, e.g. "navigator firefox".
+func wmClass(X *xgb.Conn, win xproto.Window) string {
+	r, err := xproto.GetProperty(X, false, win, xproto.AtomWmClass, xproto.AtomString, 0, 256).Reply()
+	if err != nil {
+		return ""
+	}
+	parts := strings.Split(strings.TrimRight(string(r.Value), "\x00"), "\x00")
+	return strings.ToLower(strings.Join(parts, " "))
+}
+
+// windowName returns the title of win, preferring the UTF-8 _NET_WM_NAME.
+func windowName(X *xgb.Conn, win xproto.Window) string {
+	if atom, err := internAtom(X, "_NET_WM_NAME"); err == nil {
+		if r, err := xproto.GetProperty(X, false, win, atom, xproto.GetPropertyTypeAny, 0, 256).Reply(); err == nil && len(r.Value) > 0 {
+			return string(r.Value)
+		}
+	}
+	r, err := xproto.GetProperty(X, false, win, xproto.AtomWmName, xproto.GetPropertyTypeAny, 0, 256).Reply()
+	if err != nil {
+		return ""
+	}
+	return string(r.Value)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+)
+
+const backendXrandr = "xrandr"
+
+// xrandrBackend drives every lit output with xrandr --gamma and
+// --brightness, for X11 machines with nothing else installed. xrandr
+// only takes a gamma per channel, so the white point is folded into it:
+// each channel's exponent is chosen so mid-grey lands where scaling it by
+// the white point would put it.
+type xrandrBackend struct {
+	u *uiState
+}
+
+func (b *xrandrBackend) Name() string { return backendXrandr }
+
+func (b *xrandrBackend) Capabilities() Capabilities {
+	return Capabilities{Temperature: true, Brightness: true, Gamma: true, ChannelGamma: true, WhitePoint: true, Remote: true}
+}
+
+func (b *xrandrBackend) Available() bool {
+	return hasCommand("xrandr") && !waylandSession()
+}
+
+func (b *xrandrBackend) Apply(ctx context.Context, s Settings) error {
+	wr, wg, wb := s.white()
+	gr, gg, gb := s.channelGamma()
+	gamma := fmt.Sprintf("%.3f:%.3f:%.3f", xrandrGamma(wr, gr), xrandrGamma(wg, gg), xrandrGamma(wb, gb))
+	return b.each(ctx, "--gamma", gamma, "--brightness", fmt.Sprintf("%.2f", s.Brightness))
+}
+
+func (b *xrandrBackend) Reset(ctx context.Context) error {
+	return b.each(ctx, "--gamma", "1:1:1", "--brightness", "1")
+}
+
+// each runs xrandr --output NAME args... for every lit output.
+func (b *xrandrBackend) each(ctx context.Context, args ...string) error {
+	out, err := b.u.exec(ctx, "xrandr", "--current")
+	if err != nil {
+		return commandError(out, err)
+	}
+	outputs := litOutputs.FindAllSubmatch(out, -1)
+	if len(outputs) == 0 {
+		return errors.New("xrandr lists no connected outputs")
+	}
+	for _, m := range outputs {
+		if out, err := b.u.exec(ctx, "xrandr", append([]string{"--output", string(m[1])}, args...)...); err != nil {
+			return commandError(out, err)
+		}
+	}
+	return nil
+}
+
+// litOutputs matches the xrandr --current lines of connected outputs
+// that have a mode set, e.g. "HDMI-1 connected primary 1920x1080+0+0".
+var litOutputs = regexp.MustCompile(`(?m)^(\S+) connected (?:primary )?\d+x\d+\+`)
+
+// xrandrGamma returns the xrandr gamma that, on top of gamma, scales
+// mid-grey by w. xrandr raises each entry to 1/gamma, so 1/gamma has to
+// take log2 w off: 0.5^(1/g') = w·0.5^(1/g).
+func xrandrGamma(w, gamma float64) float64 {
+	w = math.Max(w, 0.01) // keep a fully removed channel finite
+	return math.Max(gamma/(1-gamma*math.Log2(w)), 0.1)
+}
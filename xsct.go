@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+const backendXsct = "xsct"
+
+// xsctBackend runs xsct, a tiny X11 tool that sets a temperature and a
+// brightness and nothing else.
+type xsctBackend struct {
+	u *uiState
+}
+
+func (b *xsctBackend) Name() string { return backendXsct }
+
+func (b *xsctBackend) Capabilities() Capabilities {
+	return Capabilities{Temperature: true, Brightness: true, Remote: true}
+}
+
+// Limits: xsct computes its ramps for 1000K to 10000K.
+func (b *xsctBackend) Limits() Ranges {
+	l := redshiftLimits
+	l.TempMax = 10000
+	return l
+}
+
+func (b *xsctBackend) Available() bool {
+	return hasCommand("xsct") && !waylandSession()
+}
+
+func (b *xsctBackend) Apply(ctx context.Context, s Settings) error {
+	// xsct [temperature] [brightness], brightness from 0 to 1
+	if out, err := b.u.exec(ctx, "xsct", fmt.Sprint(s.TempK), fmt.Sprintf("%.2f", s.Brightness)); err != nil {
+		return commandError(out, err)
+	}
+	return nil
+}
+
+// Reset runs xsct 0, which it takes as 6500K at full brightness.
+func (b *xsctBackend) Reset(ctx context.Context) error {
+	if out, err := b.u.exec(ctx, "xsct", "0"); err != nil {
+		return commandError(out, err)
+	}
+	return nil
+}